@@ -36,3 +36,26 @@ provider "remote" {
     password = "password"
   }
 }
+
+# Multiple 'proxy_conn' blocks are connected through in the order they are defined.
+provider "remote" {
+  alias = "server3"
+
+  conn {
+    host     = "10.1.0.20"
+    user     = "john"
+    password = "password"
+  }
+
+  proxy_conn {
+    host     = "192.168.0.1"
+    user     = "gateway"
+    password = "password"
+  }
+
+  proxy_conn {
+    host     = "10.1.0.1"
+    user     = "gateway"
+    password = "password"
+  }
+}
@@ -0,0 +1,25 @@
+resource "remote_directory" "conf_d" {
+  conn {
+    host        = "10.0.0.12"
+    port        = 22
+    user        = "john"
+    private_key = "<ssh private key>"
+    sudo        = true
+  }
+
+  path              = "/etc/myapp/conf.d"
+  permissions       = "0750"
+  owner_name        = "root"
+  group_name        = "myapp"
+  delete_on_destroy = "empty"
+}
+
+resource "remote_directory" "data" {
+  provider = remote.server1
+
+  path                = "/var/lib/myapp"
+  owner_name          = "myapp"
+  group_name          = "myapp"
+  recursive_ownership = true
+  delete_on_destroy   = "never"
+}
@@ -0,0 +1,14 @@
+resource "remote_directory_sync" "site" {
+  conn {
+    host        = "10.0.0.12"
+    port        = 22
+    user        = "www"
+    private_key = "<ssh private key>"
+  }
+
+  source            = "${path.module}/public"
+  path              = "/var/www/site"
+  include           = ["*.html", "assets"]
+  exclude           = ["*.map", "drafts"]
+  delete_extraneous = true
+}
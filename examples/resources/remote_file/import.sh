@@ -0,0 +1,3 @@
+# Files are imported with the connection of the provider, the ID is
+# [proxy_host:proxy_port|...][user@]host:port:path
+terraform import remote_file.server1_bashrc john@10.0.0.13:22:/home/john/.bashrc
@@ -0,0 +1,11 @@
+resource "remote_symlink" "current" {
+  conn {
+    host        = "10.0.0.12"
+    port        = 22
+    user        = "deploy"
+    private_key = "<ssh private key>"
+  }
+
+  path   = "/srv/myapp/current"
+  target = "releases/${var.version}"
+}
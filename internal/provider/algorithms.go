@@ -0,0 +1,94 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"golang.org/x/crypto/ssh"
+)
+
+// Algorithms implemented by the ssh client, including legacy ones not enabled by default.
+var (
+	supportedKexAlgorithms = []string{
+		"curve25519-sha256",
+		"curve25519-sha256@libssh.org",
+		"ecdh-sha2-nistp256",
+		"ecdh-sha2-nistp384",
+		"ecdh-sha2-nistp521",
+		"diffie-hellman-group14-sha256",
+		"diffie-hellman-group14-sha1",
+		"diffie-hellman-group1-sha1",
+		"diffie-hellman-group-exchange-sha256",
+		"diffie-hellman-group-exchange-sha1",
+	}
+	supportedCiphers = []string{
+		"aes128-gcm@openssh.com",
+		"chacha20-poly1305@openssh.com",
+		"aes128-ctr",
+		"aes192-ctr",
+		"aes256-ctr",
+		"aes128-cbc",
+		"3des-cbc",
+		"arcfour256",
+		"arcfour128",
+		"arcfour",
+	}
+	supportedMACs = []string{
+		"hmac-sha2-256-etm@openssh.com",
+		"hmac-sha2-256",
+		"hmac-sha1",
+		"hmac-sha1-96",
+	}
+	supportedHostKeyAlgorithms = []string{
+		ssh.CertAlgoRSASHA512v01,
+		ssh.CertAlgoRSASHA256v01,
+		ssh.CertAlgoRSAv01,
+		ssh.CertAlgoDSAv01,
+		ssh.CertAlgoECDSA256v01,
+		ssh.CertAlgoECDSA384v01,
+		ssh.CertAlgoECDSA521v01,
+		ssh.CertAlgoED25519v01,
+		ssh.KeyAlgoECDSA256,
+		ssh.KeyAlgoECDSA384,
+		ssh.KeyAlgoECDSA521,
+		ssh.KeyAlgoRSASHA512,
+		ssh.KeyAlgoRSASHA256,
+		ssh.KeyAlgoRSA,
+		ssh.KeyAlgoDSA,
+		ssh.KeyAlgoED25519,
+	}
+)
+
+func algorithmsSchema(description string, supported []string) *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Elem: &schema.Schema{
+			Type:         schema.TypeString,
+			ValidateFunc: validation.StringInSlice(supported, false),
+		},
+		Description: description,
+	}
+}
+
+// setAlgorithmsFromResourceData restricts the algorithms of clientConfig to the configured ones,
+// leaving the defaults of the ssh client for those that aren't configured.
+func setAlgorithmsFromResourceData(prefixKey func(string) string, d *schema.ResourceData, clientConfig *ssh.ClientConfig) {
+	clientConfig.KeyExchanges = resourceStringList(d, prefixKey("kex_algorithms"))
+	clientConfig.Ciphers = resourceStringList(d, prefixKey("ciphers"))
+	clientConfig.MACs = resourceStringList(d, prefixKey("macs"))
+	clientConfig.HostKeyAlgorithms = resourceStringList(d, prefixKey("host_key_algorithms"))
+}
+
+// resourceStringList returns the strings of the list at key, or nil when the list is empty.
+func resourceStringList(d *schema.ResourceData, key string) []string {
+	list, ok := d.GetOk(key)
+	if !ok {
+		return nil
+	}
+
+	strings := []string{}
+	for _, item := range list.([]interface{}) {
+		strings = append(strings, item.(string))
+	}
+	return strings
+}
@@ -0,0 +1,155 @@
+package provider
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// WriteFileAtomic replaces the file at path by streaming content to a temporary file in the same
+// directory, which is only readable by the writing user until owner, group and permissions are
+// applied, and renaming it over path. The file at path is left intact when any step fails.
+func (c *RemoteClient) WriteFileAtomic(content io.Reader, path string, permissions string, owner string, group string, become *Become) error {
+	err := validatePermissions(permissions)
+	if err != nil {
+		return err
+	}
+	if owner != "" {
+		err = validateOwner(owner)
+		if err != nil {
+			return err
+		}
+	}
+	if group != "" {
+		err = validateGroup(group)
+		if err != nil {
+			return err
+		}
+	}
+
+	if become != nil {
+		return c.WriteFileAtomicShell(content, path, permissions, owner, group, become)
+	}
+	return c.WriteFileAtomicSFTP(content, path, permissions, owner, group)
+}
+
+func (c *RemoteClient) WriteFileAtomicSFTP(content io.Reader, filePath string, permissions string, owner string, group string) (err error) {
+	mode, err := strconv.ParseUint(permissions, 8, 32)
+	if err != nil {
+		return err
+	}
+
+	sftpClient, err := c.GetSFTPClient()
+	if err != nil {
+		return err
+	}
+
+	marker, err := randomMarker()
+	if err != nil {
+		return err
+	}
+	tempPath := path.Join(path.Dir(filePath), fmt.Sprintf(".%s.%s", path.Base(filePath), marker))
+
+	file, err := sftpClient.OpenFile(tempPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL)
+	if err != nil {
+		return fmt.Errorf("couldn't create temporary file: %s", err.Error())
+	}
+	defer func() {
+		if err != nil {
+			file.Close()
+			sftpClient.Remove(tempPath)
+		}
+	}()
+
+	// Restrict access before any content is written
+	err = file.Chmod(0600)
+	if err != nil {
+		return fmt.Errorf("couldn't restrict permissions of temporary file: %s", err.Error())
+	}
+
+	_, err = io.Copy(file, content)
+	if err != nil {
+		return fmt.Errorf("couldn't write temporary file: %s", err.Error())
+	}
+
+	if owner != "" || group != "" {
+		stat, err := c.StatFileSFTP(tempPath)
+		if err != nil {
+			return err
+		}
+
+		uid, gid := int(stat.UID), int(stat.GID)
+		if owner != "" {
+			uid, err = c.userID(owner)
+			if err != nil {
+				return err
+			}
+		}
+		if group != "" {
+			gid, err = c.groupID(group)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = file.Chown(uid, gid)
+		if err != nil {
+			return fmt.Errorf("couldn't change owner of temporary file: %s", err.Error())
+		}
+	}
+
+	err = file.Chmod(os.FileMode(mode))
+	if err != nil {
+		return fmt.Errorf("couldn't change permissions of temporary file: %s", err.Error())
+	}
+
+	_, ok := sftpClient.HasExtension("fsync@openssh.com")
+	if ok {
+		err = file.Sync()
+		if err != nil {
+			return fmt.Errorf("couldn't sync temporary file: %s", err.Error())
+		}
+	}
+
+	err = file.Close()
+	if err != nil {
+		return fmt.Errorf("couldn't close temporary file: %s", err.Error())
+	}
+
+	err = sftpClient.PosixRename(tempPath, filePath)
+	if err != nil {
+		return fmt.Errorf("couldn't replace file: %s", err.Error())
+	}
+	return nil
+}
+
+// WriteFileAtomicShell does the replacement in a single script. mktemp creates the temporary
+// file with permissions 0600.
+func (c *RemoteClient) WriteFileAtomicShell(content io.Reader, filePath string, permissions string, owner string, group string, become *Become) error {
+	dir, base := path.Dir(filePath), path.Base(filePath)
+
+	steps := []string{
+		`cat > "$tmp"`,
+	}
+	if owner != "" {
+		steps = append(steps, shellCommand("chown", owner, "--")+` "$tmp"`)
+	}
+	if group != "" {
+		steps = append(steps, shellCommand("chgrp", group, "--")+` "$tmp"`)
+	}
+	steps = append(steps,
+		shellCommand("chmod", permissions, "--")+` "$tmp"`,
+		`{ sync -- "$tmp" 2>/dev/null || sync; }`,
+		`mv -f -- "$tmp" `+shellQuote(filePath),
+	)
+
+	cmd := fmt.Sprintf(`tmp=$(mktemp %s) || exit 1; if %s; then exit 0; fi; rm -f -- "$tmp"; exit 1`,
+		shellQuote(path.Join(dir, "."+base+".XXXXXX")),
+		strings.Join(steps, " && "))
+
+	_, err := c.runCommand(cmd, content, become)
+	return err
+}
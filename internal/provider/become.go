@@ -0,0 +1,258 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	becomeMethodSudo = "sudo"
+	becomeMethodSu   = "su"
+	becomeMethodDoas = "doas"
+)
+
+var becomeMethods = []string{becomeMethodSudo, becomeMethodSu, becomeMethodDoas}
+
+var becomePromptRegexp = regexp.MustCompile(`(?i)password[^\n]*:\s*$`)
+
+var becomeSchemaResource = &schema.Resource{
+	Schema: map[string]*schema.Schema{
+		"method": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      becomeMethodSudo,
+			ValidateFunc: validation.StringInSlice(becomeMethods, false),
+			Description:  "The command used to run commands as `user`, one of `sudo`, `su` or `doas`.",
+		},
+		"user": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Default:     "root",
+			Description: "The user to run commands as.",
+		},
+		"password": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Sensitive:   true,
+			Description: "The password to answer the password prompt of `method` with. Commands are run in a pseudo-terminal when set.",
+		},
+	},
+}
+
+// Become describes how to run commands as another user on the remote host.
+type Become struct {
+	Method   string
+	User     string
+	Password string
+}
+
+// becomeFromResourceData returns how to gain privileges on the remote host, nil if commands are
+// to be run as the login user.
+func becomeFromResourceData(d *schema.ResourceData) (*Become, error) {
+	sudo := d.Get("conn.0.sudo").(bool)
+
+	_, ok := d.GetOk("conn.0.become")
+	if !ok {
+		if sudo {
+			return &Become{Method: becomeMethodSudo, User: "root"}, nil
+		}
+		return nil, nil
+	}
+
+	if sudo {
+		return nil, errors.New("only one of sudo and become can be set")
+	}
+
+	return &Become{
+		Method:   d.Get("conn.0.become.0.method").(string),
+		User:     d.Get("conn.0.become.0.user").(string),
+		Password: d.Get("conn.0.become.0.password").(string),
+	}, nil
+}
+
+// command wraps cmd to be run as the become user.
+func (b *Become) command(cmd string) string {
+	nonInteractive := ""
+	if b.Password == "" {
+		nonInteractive = "-n "
+	}
+
+	switch b.Method {
+	case becomeMethodSu:
+		return fmt.Sprintf("su -s /bin/sh -c %s %s", shellQuote(cmd), shellQuote(b.User))
+	case becomeMethodDoas:
+		return fmt.Sprintf("doas %s-u %s sh -c %s", nonInteractive, shellQuote(b.User), shellQuote(cmd))
+	default:
+		return fmt.Sprintf("sudo %s-p 'password: ' -u %s -- sh -c %s", nonInteractive, shellQuote(b.User), shellQuote(cmd))
+	}
+}
+
+// runCommand runs cmd on the remote host as the become user, or as the login user when become
+// is nil, and returns its standard output. The command reads stdin when it is not nil.
+func (c *RemoteClient) runCommand(cmd string, stdin io.Reader, become *Become) ([]byte, error) {
+	if become != nil && become.Password != "" {
+		return c.runCommandWithPassword(cmd, stdin, become)
+	}
+
+	session, err := c.sshClient.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	if become != nil {
+		cmd = become.command(cmd)
+	}
+
+	var stdout, stderr bytes.Buffer
+	session.Stdin = stdin
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+
+	err = session.Run(cmd)
+	if err != nil {
+		return nil, Error{
+			cmd:    cmd,
+			err:    err,
+			stderr: stderr.Bytes(),
+		}
+	}
+	return stdout.Bytes(), nil
+}
+
+// runCommandWithPassword runs cmd as the become user in a pseudo-terminal, answering the password
+// prompt of the become method. The command prints a random marker once privileges are gained,
+// everything after it is the output of cmd. Since a terminal isn't binary-safe, stdin is sent
+// base64 encoded and decoded on the remote host.
+func (c *RemoteClient) runCommandWithPassword(cmd string, stdin io.Reader, become *Become) ([]byte, error) {
+	session, err := c.sshClient.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	modes := ssh.TerminalModes{
+		ssh.ECHO:  0,
+		ssh.OPOST: 0,
+	}
+	err = session.RequestPty("dumb", 24, 80, modes)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't request a pseudo-terminal: %s", err.Error())
+	}
+
+	input, err := session.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	output, err := session.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	marker, err := randomMarker()
+	if err != nil {
+		return nil, err
+	}
+
+	inner := cmd
+	if stdin != nil {
+		inner = fmt.Sprintf("base64 -d | { %s; }", cmd)
+	}
+	wrapped := become.command(fmt.Sprintf("echo %s; %s", marker, inner))
+
+	err = session.Start(wrapped)
+	if err != nil {
+		return nil, Error{cmd: cmd, err: err}
+	}
+
+	reader := bufio.NewReader(output)
+	prompt := bytes.Buffer{}
+	passwordSent := false
+	for !bytes.HasSuffix(prompt.Bytes(), []byte(marker+"\n")) {
+		b, err := reader.ReadByte()
+		if err != nil {
+			waitErr := session.Wait()
+			if waitErr == nil {
+				waitErr = errors.New("command ended before gaining privileges")
+			}
+			return nil, Error{cmd: cmd, err: waitErr, stderr: prompt.Bytes()}
+		}
+		prompt.WriteByte(b)
+
+		if becomePromptRegexp.Match(prompt.Bytes()) {
+			if passwordSent {
+				return nil, Error{cmd: cmd, err: errors.New("incorrect become password"), stderr: prompt.Bytes()}
+			}
+			_, err = io.WriteString(input, become.Password+"\n")
+			if err != nil {
+				return nil, err
+			}
+			passwordSent = true
+			prompt.Reset()
+		}
+	}
+
+	if stdin != nil {
+		go func() {
+			writeBase64Lines(input, stdin)
+			// End of transmission at the start of a line ends the input of the terminal
+			io.WriteString(input, "\x04")
+		}()
+	}
+
+	content, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	err = session.Wait()
+	if err != nil {
+		return nil, Error{cmd: cmd, err: err, stderr: content}
+	}
+	return content, nil
+}
+
+// writeBase64Lines writes the base64 encoding of r to w in lines short enough for terminals.
+func writeBase64Lines(w io.Writer, r io.Reader) error {
+	chunk := make([]byte, 57)
+	line := make([]byte, base64.StdEncoding.EncodedLen(len(chunk))+1)
+	for {
+		n, err := io.ReadFull(r, chunk)
+		if n > 0 {
+			base64.StdEncoding.Encode(line, chunk[:n])
+			encodedLen := base64.StdEncoding.EncodedLen(n)
+			line[encodedLen] = '\n'
+			_, writeErr := w.Write(line[:encodedLen+1])
+			if writeErr != nil {
+				return writeErr
+			}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func randomMarker() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("couldn't generate marker: %s", err.Error())
+	}
+	return "terraform-provider-remote-" + hex.EncodeToString(b), nil
+}
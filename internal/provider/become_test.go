@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestBecomeCommand(t *testing.T) {
+	tests := []struct {
+		become   Become
+		expected string
+	}{
+		{
+			become:   Become{Method: becomeMethodSudo, User: "root"},
+			expected: `sudo -n -p 'password: ' -u 'root' -- sh -c 'cat '"'"'/tmp/a b'"'"''`,
+		},
+		{
+			become:   Become{Method: becomeMethodSudo, User: "bob", Password: "pwd"},
+			expected: `sudo -p 'password: ' -u 'bob' -- sh -c 'cat '"'"'/tmp/a b'"'"''`,
+		},
+		{
+			become:   Become{Method: becomeMethodDoas, User: "root"},
+			expected: `doas -n -u 'root' sh -c 'cat '"'"'/tmp/a b'"'"''`,
+		},
+		{
+			become:   Become{Method: becomeMethodSu, User: "root", Password: "password"},
+			expected: `su -s /bin/sh -c 'cat '"'"'/tmp/a b'"'"'' 'root'`,
+		},
+	}
+	for _, test := range tests {
+		cmd := test.become.command("cat '/tmp/a b'")
+		if cmd != test.expected {
+			t.Errorf("expected %s for %+v, got %s", test.expected, test.become, cmd)
+		}
+	}
+}
+
+func TestWriteBase64Lines(t *testing.T) {
+	content := bytes.Repeat([]byte{0, 1, 2, 0xff, '\n', 4}, 100)
+
+	output := bytes.Buffer{}
+	err := writeBase64Lines(&output, bytes.NewReader(content))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	lines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+	decoded := []byte{}
+	for _, line := range lines {
+		if len(line) > 76 {
+			t.Errorf("expected lines of at most 76 characters, got %d", len(line))
+		}
+		part, err := base64.StdEncoding.DecodeString(line)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		decoded = append(decoded, part...)
+	}
+
+	if !bytes.Equal(decoded, content) {
+		t.Errorf("expected decoded lines to equal the content")
+	}
+}
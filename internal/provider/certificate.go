@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"golang.org/x/crypto/ssh"
+)
+
+func certificateFromResourceData(prefixKey func(string) string, d *schema.ResourceData) (*ssh.Certificate, error) {
+	certificate, hasCertificate := d.GetOk(prefixKey("certificate"))
+	certificatePath, hasCertificatePath := d.GetOk(prefixKey("certificate_path"))
+
+	if hasCertificate && hasCertificatePath {
+		return nil, errors.New("only one of certificate and certificate_path can be set")
+	}
+
+	var content []byte
+	if hasCertificate {
+		content = []byte(certificate.(string))
+	} else if hasCertificatePath {
+		var err error
+		content, err = ioutil.ReadFile(certificatePath.(string))
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read certificate: %s", err.Error())
+		}
+	} else {
+		return nil, nil
+	}
+
+	key, _, _, _, err := ssh.ParseAuthorizedKey(content)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse certificate: %s", err.Error())
+	}
+
+	cert, ok := key.(*ssh.Certificate)
+	if !ok {
+		return nil, fmt.Errorf("couldn't parse certificate: %s key is not a certificate", key.Type())
+	}
+	if cert.CertType != ssh.UserCert {
+		return nil, errors.New("couldn't parse certificate: not a user certificate")
+	}
+
+	return cert, nil
+}
+
+// certSigner returns a signer presenting cert when it was issued for the key of signer,
+// otherwise signer is returned unchanged.
+func certSigner(signer ssh.Signer, cert *ssh.Certificate) (ssh.Signer, bool) {
+	if cert == nil || !bytes.Equal(cert.Key.Marshal(), signer.PublicKey().Marshal()) {
+		return signer, false
+	}
+
+	certSigner, err := ssh.NewCertSigner(cert, signer)
+	if err != nil {
+		return signer, false
+	}
+
+	return certSigner, true
+}
+
+// certSignersCallback wraps signers of an SSH agent so that the key cert was issued for
+// presents it.
+func certSignersCallback(signers func() ([]ssh.Signer, error), cert *ssh.Certificate) func() ([]ssh.Signer, error) {
+	if cert == nil {
+		return signers
+	}
+
+	return func() ([]ssh.Signer, error) {
+		agentSigners, err := signers()
+		if err != nil {
+			return nil, err
+		}
+
+		result := make([]ssh.Signer, 0, len(agentSigners))
+		for _, signer := range agentSigners {
+			signer, _ = certSigner(signer, cert)
+			result = append(result, signer)
+		}
+		return result, nil
+	}
+}
@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	permissionsRegexp = regexp.MustCompile(`^[0-7]{3,4}$`)
+	// ownerRegexp matches numeric IDs and the user and group names allowed by shadow-utils.
+	ownerRegexp = regexp.MustCompile(`^([0-9]+|[a-zA-Z_][a-zA-Z0-9_.-]*\$?)$`)
+)
+
+// shellCommand builds a command line running name with args, each quoted as a single word.
+func shellCommand(name string, args ...string) string {
+	words := []string{name}
+	for _, arg := range args {
+		words = append(words, shellQuote(arg))
+	}
+	return strings.Join(words, " ")
+}
+
+// shellQuote quotes s as a single word for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
+
+func validatePermissions(permissions string) error {
+	if !permissionsRegexp.MatchString(permissions) {
+		return fmt.Errorf("invalid permissions %q, expected an octal mode like 0644", permissions)
+	}
+	return nil
+}
+
+func validateOwner(owner string) error {
+	if !ownerRegexp.MatchString(owner) {
+		return fmt.Errorf("invalid owner %q, expected a user name or numeric ID", owner)
+	}
+	return nil
+}
+
+func validateGroup(group string) error {
+	if !ownerRegexp.MatchString(group) {
+		return fmt.Errorf("invalid group %q, expected a group name or numeric ID", group)
+	}
+	return nil
+}
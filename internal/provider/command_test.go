@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"os/exec"
+	"testing"
+)
+
+var hostilePaths = []string{
+	"/tmp/file with spaces.txt",
+	"/tmp/it's.txt",
+	"/tmp/$(touch /tmp/pwned).txt",
+	"/tmp/`touch /tmp/pwned`.txt",
+	"/tmp/a; rm -rf /",
+	"/tmp/a && reboot",
+	"/tmp/a | cat /etc/shadow",
+	"/tmp/$HOME/${PATH}",
+	"/tmp/\"quoted\"",
+	"/tmp/back\\slash",
+	"/tmp/new\nline",
+	"/tmp/*",
+	"-rf",
+	"--",
+	"",
+}
+
+func TestShellCommandQuotesArguments(t *testing.T) {
+	for _, path := range hostilePaths {
+		cmd := shellCommand("printf", "%s", path)
+
+		output, err := exec.Command("sh", "-c", cmd).Output()
+		if err != nil {
+			t.Fatalf("couldn't run %s: %s", cmd, err.Error())
+		}
+		if string(output) != path {
+			t.Errorf("expected %s to print %q, got %q", cmd, path, string(output))
+		}
+	}
+}
+
+func TestShellCommandQuotesArgumentsTwice(t *testing.T) {
+	// Commands run through become are quoted again as an argument of sh -c
+	for _, path := range hostilePaths {
+		cmd := shellCommand("sh", "-c", shellCommand("printf", "%s", path))
+
+		output, err := exec.Command("sh", "-c", cmd).Output()
+		if err != nil {
+			t.Fatalf("couldn't run %s: %s", cmd, err.Error())
+		}
+		if string(output) != path {
+			t.Errorf("expected %s to print %q, got %q", cmd, path, string(output))
+		}
+	}
+}
+
+func TestValidatePermissions(t *testing.T) {
+	for _, permissions := range []string{"644", "0644", "0777", "4755"} {
+		err := validatePermissions(permissions)
+		if err != nil {
+			t.Errorf("expected %q to be valid, got %s", permissions, err.Error())
+		}
+	}
+	for _, permissions := range []string{"", "0888", "u+x", "0644; reboot", "-R 0777", "07777"} {
+		err := validatePermissions(permissions)
+		if err == nil {
+			t.Errorf("expected %q to be invalid", permissions)
+		}
+	}
+}
+
+func TestValidateOwnerAndGroup(t *testing.T) {
+	for _, owner := range []string{"root", "1000", "www-data", "_apt", "user.name", "machine$"} {
+		err := validateOwner(owner)
+		if err != nil {
+			t.Errorf("expected owner %q to be valid, got %s", owner, err.Error())
+		}
+		err = validateGroup(owner)
+		if err != nil {
+			t.Errorf("expected group %q to be valid, got %s", owner, err.Error())
+		}
+	}
+	for _, owner := range []string{"", "root:root", "-R", "bob; reboot", "$(id)", "a b", "1000x"} {
+		if validateOwner(owner) == nil {
+			t.Errorf("expected owner %q to be invalid", owner)
+		}
+		if validateGroup(owner) == nil {
+			t.Errorf("expected group %q to be invalid", owner)
+		}
+	}
+}
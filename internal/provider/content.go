@@ -0,0 +1,107 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// configuredContent returns the content of file given by content or content_base64 in config.
+// It is read from the configuration since the value in state is empty in hash-only mode. known is
+// false while the content depends on values not known before apply.
+func configuredContent(config cty.Value) (content string, known bool, err error) {
+	if !config.IsKnown() {
+		return "", false, nil
+	}
+	if config.IsNull() {
+		return "", true, nil
+	}
+
+	value := config.GetAttr("content")
+	if !value.IsKnown() {
+		return "", false, nil
+	}
+	if !value.IsNull() {
+		return value.AsString(), true, nil
+	}
+
+	value = config.GetAttr("content_base64")
+	if !value.IsKnown() {
+		return "", false, nil
+	}
+	if value.IsNull() {
+		return "", true, nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(value.AsString())
+	if err != nil {
+		return "", true, fmt.Errorf("unable to decode content_base64: %s", err.Error())
+	}
+	return string(decoded), true, nil
+}
+
+// suppressHashOnlyContent keeps content out of state in hash-only mode. Its changes are planned
+// by resourceRemoteFileCustomizeDiff through content_sha256 instead.
+func suppressHashOnlyContent(k, old, new string, d *schema.ResourceData) bool {
+	return d.Get("hash_only").(bool)
+}
+
+// resourceRemoteFileCustomizeDiff plans an update when the hash of the configured content or the
+// local source file differs from the one in state, which is the hash of the remote file after a
+// refresh.
+func resourceRemoteFileCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("source") {
+		return setNewComputed(d, "source_sha256", "content_sha256", "size")
+	}
+
+	var digest *contentDigest
+	source, fromSource := d.GetOk("source")
+	if fromSource {
+		var err error
+		digest, err = localFileDigest(source.(string))
+		if err != nil {
+			return err
+		}
+
+		if d.Get("source_sha256").(string) != digest.sha256() {
+			err = d.SetNew("source_sha256", digest.sha256())
+			if err != nil {
+				return err
+			}
+		}
+	} else {
+		content, known, err := configuredContent(d.GetRawConfig())
+		if err != nil {
+			return err
+		}
+		if !known {
+			return setNewComputed(d, "content_sha256", "size")
+		}
+
+		digest = newContentDigest()
+		io.WriteString(digest, content)
+	}
+
+	if d.Get("content_sha256").(string) != digest.sha256() || int64(d.Get("size").(int)) != digest.size {
+		err := d.SetNew("content_sha256", digest.sha256())
+		if err != nil {
+			return err
+		}
+		return d.SetNew("size", int(digest.size))
+	}
+	return nil
+}
+
+func setNewComputed(d *schema.ResourceDiff, keys ...string) error {
+	for _, key := range keys {
+		err := d.SetNewComputed(key)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
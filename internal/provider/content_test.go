@@ -0,0 +1,44 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+)
+
+func TestConfiguredContent(t *testing.T) {
+	tests := []struct {
+		content       cty.Value
+		contentBase64 cty.Value
+		expected      string
+		known         bool
+	}{
+		{cty.StringVal("text"), cty.NullVal(cty.String), "text", true},
+		{cty.NullVal(cty.String), cty.StringVal("AAEC/w=="), "\x00\x01\x02\xff", true},
+		{cty.NullVal(cty.String), cty.NullVal(cty.String), "", true},
+		{cty.UnknownVal(cty.String), cty.NullVal(cty.String), "", false},
+		{cty.NullVal(cty.String), cty.UnknownVal(cty.String), "", false},
+	}
+
+	for _, test := range tests {
+		config := cty.ObjectVal(map[string]cty.Value{
+			"content":        test.content,
+			"content_base64": test.contentBase64,
+		})
+		content, known, err := configuredContent(config)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err.Error())
+		}
+		if content != test.expected || known != test.known {
+			t.Errorf("expected %q (known %t), got %q (known %t)", test.expected, test.known, content, known)
+		}
+	}
+
+	_, _, err := configuredContent(cty.ObjectVal(map[string]cty.Value{
+		"content":        cty.NullVal(cty.String),
+		"content_base64": cty.StringVal("not base64"),
+	}))
+	if err == nil {
+		t.Error("expected invalid content_base64 to fail")
+	}
+}
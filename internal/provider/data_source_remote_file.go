@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"encoding/base64"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -32,6 +33,11 @@ func dataSourceRemoteFile() *schema.Resource {
 				Type:        schema.TypeString,
 				Computed:    true,
 			},
+			"content_base64": {
+				Description: "Base64 encoded content of file, for binary files.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
 			"permissions": {
 				Description: "Permissions of file (in octal form).",
 				Type:        schema.TypeString,
@@ -69,63 +75,34 @@ func dataSourceRemoteFileRead(ctx context.Context, d *schema.ResourceData, meta
 
 	setResourceID(d, conn)
 
+	become, err := becomeFromResourceData(conn)
+	if err != nil {
+		return diag.Errorf(err.Error())
+	}
+
 	client, err := meta.(*apiClient).getRemoteClient(ctx, conn)
 	if err != nil {
 		return diag.Errorf("unable to open remote client: %s", err.Error())
 	}
+	defer meta.(*apiClient).closeRemoteClient(conn)
 
-	conn_sudo, ok := conn.GetOk("conn.0.sudo")
-	sudo := ok && conn_sudo.(bool)
 	path := d.Get("path").(string)
 
-	exists, err := client.FileExists(path, sudo)
-	if err != nil {
-		return diag.Errorf("unable to check if remote file exists: %s", err.Error())
-	}
-	if !exists {
-		return diag.Errorf("cannot read file, it does not exist")
-	}
-
-	content, err := client.ReadFile(path, sudo)
+	file, err := client.LoadFile(path, become)
 	if err != nil {
 		return diag.Errorf("unable to read remote file: %s", err.Error())
 	}
-	d.Set("content", content)
-
-	permissions, err := client.ReadFilePermissions(path, sudo)
-	if err != nil {
-		return diag.Errorf("unable to read remote file permissions: %s", err.Error())
-	}
-	d.Set("permissions", permissions)
-
-	owner, err := client.ReadFileOwner(path, sudo)
-	if err != nil {
-		return diag.Errorf("unable to read remote file owner: %s", err.Error())
-	}
-	d.Set("owner", owner)
-
-	owner_name, err := client.ReadFileOwnerName(path, sudo)
-	if err != nil {
-		return diag.Errorf("unable to read remote file owner_name: %s", err.Error())
-	}
-	d.Set("owner_name", owner_name)
-
-	group, err := client.ReadFileGroup(path, sudo)
-	if err != nil {
-		return diag.Errorf("unable to read remote file group: %s", err.Error())
-	}
-	d.Set("group", group)
-
-	group_name, err := client.ReadFileGroupName(path, sudo)
-	if err != nil {
-		return diag.Errorf("unable to read remote file group_name: %s", err.Error())
+	if !file.Exists {
+		return diag.Errorf("cannot read file, it does not exist")
 	}
-	d.Set("group_name", group_name)
 
-	err = meta.(*apiClient).closeRemoteClient(conn)
-	if err != nil {
-		return diag.Errorf("unable to close remote client: %s", err.Error())
-	}
+	d.Set("content", file.Content)
+	d.Set("content_base64", base64.StdEncoding.EncodeToString([]byte(file.Content)))
+	d.Set("permissions", file.Permissions)
+	d.Set("owner", file.Owner)
+	d.Set("owner_name", file.OwnerName)
+	d.Set("group", file.Group)
+	d.Set("group_name", file.GroupName)
 
 	return diag.Diagnostics{}
 }
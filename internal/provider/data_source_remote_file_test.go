@@ -1,7 +1,9 @@
 package provider
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
 	"testing"
 
@@ -216,3 +218,407 @@ func TestAccDataSourceRemoteFileThroughProxy(t *testing.T) {
 		},
 	})
 }
+
+func TestAccDataSourceRemoteFileKnownHosts(t *testing.T) {
+	knownHostsPath := filepath.Join(t.TempDir(), "known_hosts")
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			writeFileToHost("remotehost:22", "/tmp/data_5.txt", "data_5", "root", "root")
+		},
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				// Record the key of 'remotehost'
+				Config: fmt.Sprintf(`
+				data "remote_file" "data_5" {
+					conn {
+						host = "remotehost"
+						user = "root"
+						password = "password"
+						known_hosts_path = "%s"
+						host_key_check = "accept-new"
+					}
+					path = "/tmp/data_5.txt"
+				}
+				`, knownHostsPath),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						"data.remote_file.data_5", "content", regexp.MustCompile("data_5")),
+				),
+			},
+			{
+				// Verify against the recorded key
+				Config: fmt.Sprintf(`
+				data "remote_file" "data_5" {
+					conn {
+						host = "remotehost"
+						user = "root"
+						password = "password"
+						known_hosts_path = "%s"
+						host_key_check = "strict"
+					}
+					path = "/tmp/data_5.txt"
+				}
+				`, knownHostsPath),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						"data.remote_file.data_5", "content", regexp.MustCompile("data_5")),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceRemoteFileHostKeyMismatch(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			writeFileToHost("remotehost:22", "/tmp/data_6.txt", "data_6", "root", "root")
+		},
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				// The user key of the test hosts is not their host key
+				Config: `
+				data "remote_file" "data_6" {
+					conn {
+						host = "remotehost"
+						user = "root"
+						password = "password"
+						host_key = file("../../tests/key.pub")
+					}
+					path = "/tmp/data_6.txt"
+				}
+				`,
+				ExpectError: regexp.MustCompile("does not match the expected host key"),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceRemoteFileWithCertificate(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			writeFileToHost("remotehost:22", "/tmp/data_7.txt", "data_7", "root", "root")
+		},
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				// 'bob' has no authorized keys, only the certificate grants access
+				Config: `
+				data "remote_file" "data_7" {
+					conn {
+						host = "remotehost"
+						user = "bob"
+						private_key_path = "../../tests/key"
+						certificate_path = "../../tests/key-cert.pub"
+					}
+					path = "/tmp/data_7.txt"
+				}
+				`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						"data.remote_file.data_7", "content", regexp.MustCompile("data_7")),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceRemoteFileWithSSHConfig(t *testing.T) {
+	key, err := filepath.Abs("../../tests/key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sshConfigPath := filepath.Join(t.TempDir(), "config")
+	sshConfig := fmt.Sprintf(`
+Host target
+    HostName remotehost
+    Port 1022
+    ProxyJump jump
+
+Host jump
+    HostName remotehost2
+
+Host *
+    User root
+    IdentityFile %s
+`, key)
+	err = os.WriteFile(sshConfigPath, []byte(sshConfig), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			writeFileToHost("remotehost:22", "/tmp/data_8.txt", "data_8", "root", "root")
+		},
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+				data "remote_file" "data_8" {
+					conn {
+						host = "target"
+						ssh_config = true
+						ssh_config_path = "%s"
+					}
+					path = "/tmp/data_8.txt"
+				}
+				`, sshConfigPath),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						"data.remote_file.data_8", "content", regexp.MustCompile("data_8")),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceRemoteFileWithEncryptedPrivateKey(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			writeFileToHost("remotehost:22", "/tmp/data_9.txt", "data_9", "root", "root")
+		},
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+				data "remote_file" "data_9" {
+					conn {
+						host = "remotehost"
+						user = "root"
+						private_key_path = "../../tests/key_encrypted"
+						private_key_passphrase = "wrong"
+					}
+					path = "/tmp/data_9.txt"
+				}
+				`,
+				ExpectError: regexp.MustCompile("incorrect passphrase for private key"),
+			},
+			{
+				Config: `
+				data "remote_file" "data_9" {
+					conn {
+						host = "remotehost"
+						user = "root"
+						private_key_path = "../../tests/key_encrypted"
+						private_key_passphrase = "passphrase"
+					}
+					path = "/tmp/data_9.txt"
+				}
+				`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						"data.remote_file.data_9", "content", regexp.MustCompile("data_9")),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceRemoteFileWithProxyCommand(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			writeFileToHost("remotehost:22", "/tmp/data_10.txt", "data_10", "root", "root")
+		},
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				// Port 1022 of 'remotehost' is only reachable through 'remotehost2'
+				Config: `
+				data "remote_file" "data_10" {
+					conn {
+						host = "remotehost"
+						port = 1022
+						user = "root"
+						password = "password"
+						proxy_command = "ssh -i ~/.ssh/key -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null -W %h:%p root@remotehost2"
+					}
+					path = "/tmp/data_10.txt"
+				}
+				`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						"data.remote_file.data_10", "content", regexp.MustCompile("data_10")),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceRemoteFileRetryGivesUp(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				// Nothing listens on port 2022 of 'remotehost'
+				Config: `
+				data "remote_file" "data_11" {
+					conn {
+						host = "remotehost"
+						port = 2022
+						user = "root"
+						password = "password"
+						retry {
+							timeout = 2000
+							initial_backoff = 100
+							retry_on = ["connection_refused"]
+						}
+					}
+					path = "/tmp/data_11.txt"
+				}
+				`,
+				ExpectError: regexp.MustCompile("giving up after [0-9]+ attempts"),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceRemoteFileWithAlgorithms(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			writeFileToHost("remotehost:22", "/tmp/data_12.txt", "data_12", "root", "root")
+		},
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+				data "remote_file" "data_12" {
+					conn {
+						host = "remotehost"
+						user = "root"
+						password = "password"
+						ciphers = ["blowfish-cbc"]
+					}
+					path = "/tmp/data_12.txt"
+				}
+				`,
+				ExpectError: regexp.MustCompile("to be one of"),
+			},
+			{
+				Config: `
+				data "remote_file" "data_12" {
+					conn {
+						host = "remotehost"
+						user = "root"
+						password = "password"
+						kex_algorithms = ["curve25519-sha256"]
+						ciphers = ["aes256-ctr"]
+						macs = ["hmac-sha2-256"]
+						host_key_algorithms = ["ssh-ed25519", "rsa-sha2-256"]
+					}
+					path = "/tmp/data_12.txt"
+				}
+				`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						"data.remote_file.data_12", "content", regexp.MustCompile("data_12")),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceRemoteFileWithSSHConfigAndExplicitPort(t *testing.T) {
+	key, err := filepath.Abs("../../tests/key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Port 1022 of remotehost is only reachable from remotehost2
+	sshConfigPath := filepath.Join(t.TempDir(), "config")
+	sshConfig := fmt.Sprintf(`
+Host target
+    HostName remotehost
+    Port 1022
+    User root
+    IdentityFile %s
+`, key)
+	err = os.WriteFile(sshConfigPath, []byte(sshConfig), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			writeFileToHost("remotehost:22", "/tmp/data_19.txt", "data_19", "root", "root")
+		},
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+				data "remote_file" "data_19" {
+					conn {
+						host = "target"
+						port = 22
+						ssh_config = true
+						ssh_config_path = "%s"
+					}
+					path = "/tmp/data_19.txt"
+				}
+				`, sshConfigPath),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.remote_file.data_19", "content", "data_19"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceRemoteFileWithProxyCommandAndKnownHosts(t *testing.T) {
+	knownHostsPath := filepath.Join(t.TempDir(), "known_hosts")
+	config := func(hostKeyCheck string) string {
+		return fmt.Sprintf(`
+		data "remote_file" "data_20" {
+			conn {
+				host = "remotehost"
+				port = 1022
+				user = "root"
+				password = "password"
+				proxy_command = "ssh -i ~/.ssh/key -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null -W %%h:%%p root@remotehost2"
+				known_hosts_path = "%s"
+				host_key_check = "%s"
+			}
+			path = "/tmp/data_20.txt"
+		}
+		`, knownHostsPath, hostKeyCheck)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			writeFileToHost("remotehost:22", "/tmp/data_20.txt", "data_20", "root", "root")
+		},
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				// Record the key of port 1022 of 'remotehost', reached through 'remotehost2'
+				Config: config("accept-new"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.remote_file.data_20", "content", "data_20"),
+				),
+			},
+			{
+				// Verify against the recorded key
+				Config: config("strict"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.remote_file.data_20", "content", "data_20"),
+				),
+			},
+		},
+	})
+}
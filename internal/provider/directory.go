@@ -0,0 +1,369 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/sftp"
+)
+
+// parentDirectories returns the ancestors of filePath, outermost first, without the root and
+// the current directory.
+func parentDirectories(filePath string) []string {
+	dirs := []string{}
+	for dir := path.Dir(path.Clean(filePath)); dir != "/" && dir != "."; dir = path.Dir(dir) {
+		dirs = append([]string{dir}, dirs...)
+	}
+	return dirs
+}
+
+// CreateParents creates the missing parent directories of filePath with permissions, owner and
+// group, which are left as is when empty. It returns the created directories, outermost first,
+// also when it fails part way.
+func (c *RemoteClient) CreateParents(filePath string, permissions string, owner string, group string, become *Become) ([]string, error) {
+	err := validatePermissions(permissions)
+	if err != nil {
+		return nil, err
+	}
+	if owner != "" {
+		err = validateOwner(owner)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if group != "" {
+		err = validateGroup(group)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if become != nil {
+		return c.CreateParentsShell(filePath, permissions, owner, group, become)
+	}
+	return c.CreateParentsSFTP(filePath, permissions, owner, group)
+}
+
+func (c *RemoteClient) CreateParentsSFTP(filePath string, permissions string, owner string, group string) ([]string, error) {
+	sftpClient, err := c.GetSFTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	created := []string{}
+	for _, dir := range parentDirectories(filePath) {
+		stat, err := c.StatFileSFTP(dir)
+		if err == nil {
+			if stat.Mode&unixFileTypeMask != unixFileTypeDirectory {
+				return created, fmt.Errorf("%s exists and is not a directory", dir)
+			}
+			continue
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return created, err
+		}
+
+		err = sftpClient.Mkdir(dir)
+		if err != nil {
+			return created, fmt.Errorf("couldn't create directory %s: %s", dir, err.Error())
+		}
+		created = append(created, dir)
+
+		if owner != "" {
+			err = c.ChownFileSFTP(dir, owner)
+			if err != nil {
+				return created, fmt.Errorf("couldn't change owner of %s: %s", dir, err.Error())
+			}
+		}
+		if group != "" {
+			err = c.ChgrpFileSFTP(dir, group)
+			if err != nil {
+				return created, fmt.Errorf("couldn't change group of %s: %s", dir, err.Error())
+			}
+		}
+
+		// Applied after changing ownership, which may clear setuid and setgid bits, and since
+		// the mode of mkdir is masked by the umask
+		err = c.ChmodFileSFTP(dir, permissions)
+		if err != nil {
+			return created, fmt.Errorf("couldn't change permissions of %s: %s", dir, err.Error())
+		}
+	}
+	return created, nil
+}
+
+// CreateParentsShell creates the directories in a single script, which prints the index of each
+// directory it created.
+func (c *RemoteClient) CreateParentsShell(filePath string, permissions string, owner string, group string, become *Become) ([]string, error) {
+	dirs := parentDirectories(filePath)
+
+	script := []string{}
+	for i, dir := range dirs {
+		steps := []string{
+			shellCommand("mkdir", "--", dir) + " || exit 1",
+			fmt.Sprintf("echo %d", i),
+		}
+		if owner != "" {
+			steps = append(steps, shellCommand("chown", owner, "--", dir)+" || exit 1")
+		}
+		if group != "" {
+			steps = append(steps, shellCommand("chgrp", group, "--", dir)+" || exit 1")
+		}
+		steps = append(steps, shellCommand("chmod", permissions, "--", dir)+" || exit 1")
+
+		script = append(script, fmt.Sprintf("if test ! -e %s; then %s; elif test ! -d %s; then echo %s >&2; exit 1; fi",
+			shellQuote(dir),
+			strings.Join(steps, "; "),
+			shellQuote(dir),
+			shellQuote(dir+" exists and is not a directory")))
+	}
+	if len(script) == 0 {
+		return []string{}, nil
+	}
+
+	output, runErr := c.runCommand(strings.Join(script, "; "), nil, become)
+
+	created := []string{}
+	for _, line := range strings.Fields(string(output)) {
+		i, err := strconv.Atoi(line)
+		if err == nil && i >= 0 && i < len(dirs) {
+			created = append(created, dirs[i])
+		}
+	}
+	return created, runErr
+}
+
+// RemoveEmptyDirectories removes dirs, innermost first, skipping those which are missing or not
+// empty.
+func (c *RemoteClient) RemoveEmptyDirectories(dirs []string, become *Become) error {
+	if become != nil {
+		return c.RemoveEmptyDirectoriesShell(dirs, become)
+	}
+	return c.RemoveEmptyDirectoriesSFTP(dirs)
+}
+
+func (c *RemoteClient) RemoveEmptyDirectoriesSFTP(dirs []string) error {
+	sftpClient, err := c.GetSFTPClient()
+	if err != nil {
+		return err
+	}
+
+	for i := len(dirs) - 1; i >= 0; i-- {
+		entries, err := sftpClient.ReadDir(dirs[i])
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if len(entries) > 0 {
+			continue
+		}
+
+		err = sftpClient.RemoveDirectory(dirs[i])
+		if err != nil {
+			return fmt.Errorf("couldn't remove directory %s: %s", dirs[i], err.Error())
+		}
+	}
+	return nil
+}
+
+func (c *RemoteClient) RemoveEmptyDirectoriesShell(dirs []string, become *Become) error {
+	script := []string{}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		script = append(script, fmt.Sprintf(`if test -d %s && test -z "$(ls -A -- %s)"; then %s || exit 1; fi`,
+			shellQuote(dirs[i]),
+			shellQuote(dirs[i]),
+			shellCommand("rmdir", "--", dirs[i])))
+	}
+	if len(script) == 0 {
+		return nil
+	}
+
+	_, err := c.runCommand(strings.Join(script, "; "), nil, become)
+	return err
+}
+
+// MakeDirectory creates the directory at dirPath unless it already exists and returns whether it
+// was created.
+func (c *RemoteClient) MakeDirectory(dirPath string, become *Become) (bool, error) {
+	if become != nil {
+		cmd := fmt.Sprintf("if %s; then echo exists; else %s && echo created; fi",
+			shellCommand("test", "-d", dirPath), shellCommand("mkdir", "--", dirPath))
+		output, err := c.runCommand(cmd, nil, become)
+		if err != nil {
+			return false, err
+		}
+		return strings.TrimSpace(string(output)) == "created", nil
+	}
+
+	sftpClient, err := c.GetSFTPClient()
+	if err != nil {
+		return false, err
+	}
+
+	err = sftpClient.Mkdir(dirPath)
+	if err != nil {
+		stat, statErr := c.StatFileSFTP(dirPath)
+		if statErr == nil && stat.Mode&unixFileTypeMask == unixFileTypeDirectory {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// LoadDirectory returns the attributes of the directory at dirPath, following symbolic links.
+func (c *RemoteClient) LoadDirectory(dirPath string, become *Become) (*RemoteFile, error) {
+	if become != nil {
+		cmd := fmt.Sprintf("if %s; then %s; else echo missing; fi", shellCommand("test", "-d", dirPath), statShellCommand(dirPath))
+		output, err := c.runCommand(cmd, nil, become)
+		if err != nil {
+			return nil, err
+		}
+		return parseStatOutput(dirPath, strings.TrimSpace(string(output)))
+	}
+
+	stat, err := c.StatFileSFTP(dirPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return &RemoteFile{Exists: false}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if stat.Mode&unixFileTypeMask != unixFileTypeDirectory {
+		return &RemoteFile{Exists: false}, nil
+	}
+	return c.remoteFileFromStat(stat), nil
+}
+
+// ChownRecursive changes owner and group, which are left as is when empty, of dirPath and
+// everything in it. Symbolic links are not followed.
+func (c *RemoteClient) ChownRecursive(dirPath string, owner string, group string, become *Become) error {
+	if owner == "" && group == "" {
+		return nil
+	}
+	if owner != "" {
+		err := validateOwner(owner)
+		if err != nil {
+			return err
+		}
+	}
+	if group != "" {
+		err := validateGroup(group)
+		if err != nil {
+			return err
+		}
+	}
+
+	if become != nil {
+		ownership := owner
+		if group != "" {
+			ownership = fmt.Sprintf("%s:%s", owner, group)
+		}
+		_, err := c.runCommand(shellCommand("chown", "-R", ownership, "--", dirPath), nil, become)
+		return err
+	}
+	return c.ChownRecursiveSFTP(dirPath, owner, group)
+}
+
+func (c *RemoteClient) ChownRecursiveSFTP(dirPath string, owner string, group string) error {
+	uid, gid := -1, -1
+	var err error
+	if owner != "" {
+		uid, err = c.userID(owner)
+		if err != nil {
+			return err
+		}
+	}
+	if group != "" {
+		gid, err = c.groupID(group)
+		if err != nil {
+			return err
+		}
+	}
+
+	sftpClient, err := c.GetSFTPClient()
+	if err != nil {
+		return err
+	}
+
+	walker := sftpClient.Walk(dirPath)
+	for walker.Step() {
+		err = walker.Err()
+		if err != nil {
+			return err
+		}
+		if walker.Stat().Mode()&os.ModeSymlink != 0 {
+			continue
+		}
+		stat, ok := walker.Stat().Sys().(*sftp.FileStat)
+		if !ok {
+			return fmt.Errorf("couldn't read attributes of %s", walker.Path())
+		}
+
+		fileUID, fileGID := uid, gid
+		if fileUID < 0 {
+			fileUID = int(stat.UID)
+		}
+		if fileGID < 0 {
+			fileGID = int(stat.GID)
+		}
+		if fileUID == int(stat.UID) && fileGID == int(stat.GID) {
+			continue
+		}
+
+		err = sftpClient.Chown(walker.Path(), fileUID, fileGID)
+		if err != nil {
+			return fmt.Errorf("couldn't change owner of %s: %s", walker.Path(), err.Error())
+		}
+	}
+	return nil
+}
+
+// RemoveDirectoryRecursive removes dirPath and everything in it, like rm -rf.
+func (c *RemoteClient) RemoveDirectoryRecursive(dirPath string, become *Become) error {
+	if become != nil {
+		_, err := c.runCommand(shellCommand("rm", "-rf", "--", dirPath), nil, become)
+		return err
+	}
+
+	sftpClient, err := c.GetSFTPClient()
+	if err != nil {
+		return err
+	}
+
+	// Walk lists directories before their contents, removing in reverse empties them first
+	type entry struct {
+		path string
+		dir  bool
+	}
+	entries := []entry{}
+	walker := sftpClient.Walk(dirPath)
+	for walker.Step() {
+		err = walker.Err()
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry{path: walker.Path(), dir: walker.Stat().IsDir()})
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].dir {
+			err = sftpClient.RemoveDirectory(entries[i].path)
+		} else {
+			err = sftpClient.Remove(entries[i].path)
+		}
+		if err != nil {
+			return fmt.Errorf("couldn't remove %s: %s", entries[i].path, err.Error())
+		}
+	}
+	return nil
+}
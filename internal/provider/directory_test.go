@@ -0,0 +1,23 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParentDirectories(t *testing.T) {
+	tests := map[string][]string{
+		"/etc/myapp/conf.d/app.conf": {"/etc", "/etc/myapp", "/etc/myapp/conf.d"},
+		"/etc/myapp/conf.d/":         {"/etc", "/etc/myapp"},
+		"/app.conf":                  {},
+		"conf.d/app.conf":            {"conf.d"},
+		"app.conf":                   {},
+	}
+
+	for filePath, expected := range tests {
+		dirs := parentDirectories(filePath)
+		if !reflect.DeepEqual(dirs, expected) {
+			t.Errorf("expected %q for %s, got %q", expected, filePath, dirs)
+		}
+	}
+}
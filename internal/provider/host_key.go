@@ -0,0 +1,287 @@
+package provider
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	hostKeyCheckStrict    = "strict"
+	hostKeyCheckAcceptNew = "accept-new"
+	hostKeyCheckOff       = "off"
+)
+
+var hostKeyCheckPolicies = []string{hostKeyCheckStrict, hostKeyCheckAcceptNew, hostKeyCheckOff}
+
+// knownHostsMux serializes appends to known_hosts files when accepting new host keys.
+var knownHostsMux = &sync.Mutex{}
+
+type hostKeyChecker struct {
+	policy       string
+	hostKeys     []ssh.PublicKey
+	knownHosts   ssh.HostKeyCallback
+	newHostsPath string
+}
+
+type hostKeySettings struct {
+	hostKey        string
+	knownHosts     string
+	knownHostsPath string
+	policy         string
+}
+
+func hostKeySettingsFromResourceData(prefixKey func(string) string, d *schema.ResourceData) hostKeySettings {
+	return hostKeySettings{
+		hostKey:        resourceStringWithDefault(d, prefixKey("host_key"), ""),
+		knownHosts:     resourceStringWithDefault(d, prefixKey("known_hosts"), ""),
+		knownHostsPath: resourceStringWithDefault(d, prefixKey("known_hosts_path"), ""),
+		policy:         resourceStringWithDefault(d, prefixKey("host_key_check"), ""),
+	}
+}
+
+func hostKeyCallbackFromResourceData(prefixKey func(string) string, d *schema.ResourceData, host string) (ssh.HostKeyCallback, []string, error) {
+	return hostKeySettingsFromResourceData(prefixKey, d).callback(host)
+}
+
+// callback returns the host key callback for host and the host key algorithms matching the keys
+// it checks against, nil when the defaults of the ssh client apply.
+func (s hostKeySettings) callback(host string) (ssh.HostKeyCallback, []string, error) {
+	hasHostKey := s.hostKey != ""
+	hasKnownHosts := s.knownHosts != ""
+	hasKnownHostsPath := s.knownHostsPath != ""
+
+	policy := s.policy
+	if policy == "" {
+		policy = hostKeyCheckOff
+		if hasHostKey || hasKnownHosts || hasKnownHostsPath {
+			policy = hostKeyCheckStrict
+		}
+	}
+	if policy == hostKeyCheckOff {
+		return ssh.InsecureIgnoreHostKey(), nil, nil
+	}
+
+	checker := hostKeyChecker{policy: policy}
+
+	if hasHostKey {
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(s.hostKey))
+		if err != nil {
+			return nil, nil, fmt.Errorf("couldn't parse host key: %s", err.Error())
+		}
+		checker.hostKeys = append(checker.hostKeys, key)
+	}
+
+	files := []string{}
+
+	if hasKnownHosts {
+		file, err := writeTempKnownHosts(s.knownHosts)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer os.Remove(file)
+		files = append(files, file)
+	}
+
+	path := ""
+	if hasKnownHostsPath {
+		path = s.knownHostsPath
+	} else if !hasHostKey && !hasKnownHosts {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, nil, fmt.Errorf("couldn't locate default known_hosts file: %s", err.Error())
+		}
+		path = filepath.Join(home, ".ssh", "known_hosts")
+	}
+
+	if path != "" {
+		_, err := os.Stat(path)
+		if err == nil {
+			files = append(files, path)
+		} else if !os.IsNotExist(err) || policy != hostKeyCheckAcceptNew {
+			return nil, nil, fmt.Errorf("couldn't read known_hosts file: %s", err.Error())
+		}
+		checker.newHostsPath = path
+	}
+
+	if len(files) > 0 {
+		callback, err := knownhosts.New(files...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("couldn't parse known_hosts: %s", err.Error())
+		}
+		checker.knownHosts = callback
+	}
+
+	if policy == hostKeyCheckAcceptNew && checker.newHostsPath == "" {
+		return nil, nil, fmt.Errorf("host_key_check = \"%s\" requires known_hosts_path to record new host keys", hostKeyCheckAcceptNew)
+	}
+
+	// Temporary known_hosts files have to exist while their entries are looked up
+	algorithms, err := checker.hostKeyAlgorithms(host)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return checker.check, algorithms, nil
+}
+
+// hostKeyAlgorithms returns the algorithms of the keys known for host, so that the server
+// presents one of those keys rather than the one the ssh client prefers. The defaults are kept
+// when no key is known or host keys are signed by a certificate authority.
+func (c *hostKeyChecker) hostKeyAlgorithms(host string) ([]string, error) {
+	types := map[string]bool{}
+	for _, key := range c.hostKeys {
+		types[key.Type()] = true
+	}
+
+	if c.knownHosts != nil {
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't look up known host keys: %s", err.Error())
+		}
+		signer, err := ssh.NewSignerFromKey(private)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't look up known host keys: %s", err.Error())
+		}
+
+		// Checking a key nobody knows fails with all known keys of host
+		err = c.knownHosts(host, &net.TCPAddr{IP: net.IPv4zero}, signer.PublicKey())
+		var keyErr *knownhosts.KeyError
+		if errors.As(err, &keyErr) {
+			for _, known := range keyErr.Want {
+				authority, err := isCertAuthorityLine(known.Filename, known.Line)
+				if err != nil {
+					return nil, err
+				}
+				if authority {
+					return nil, nil
+				}
+				types[known.Key.Type()] = true
+			}
+		}
+	}
+
+	algorithms := []string{}
+	for _, algorithm := range supportedHostKeyAlgorithms {
+		keyType := algorithm
+		if algorithm == ssh.KeyAlgoRSASHA512 || algorithm == ssh.KeyAlgoRSASHA256 {
+			keyType = ssh.KeyAlgoRSA
+		}
+		if types[keyType] {
+			algorithms = append(algorithms, algorithm)
+		}
+	}
+	if len(algorithms) == 0 {
+		return nil, nil
+	}
+	return algorithms, nil
+}
+
+// isCertAuthorityLine returns whether the line with number lineNumber of a known_hosts file is a
+// @cert-authority entry.
+func isCertAuthorityLine(path string, lineNumber int) (bool, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("couldn't read known_hosts file: %s", err.Error())
+	}
+
+	lines := strings.Split(string(content), "\n")
+	if lineNumber < 1 || lineNumber > len(lines) {
+		return false, nil
+	}
+	fields := strings.Fields(lines[lineNumber-1])
+	return len(fields) > 0 && fields[0] == "@cert-authority", nil
+}
+
+func (c *hostKeyChecker) check(hostname string, remote net.Addr, key ssh.PublicKey) error {
+	for _, hostKey := range c.hostKeys {
+		if bytes.Equal(hostKey.Marshal(), key.Marshal()) {
+			return nil
+		}
+	}
+
+	known := len(c.hostKeys) > 0
+	if c.knownHosts != nil {
+		err := c.knownHosts(hostname, remote, key)
+		if err == nil {
+			return nil
+		}
+
+		var keyErr *knownhosts.KeyError
+		if !errors.As(err, &keyErr) {
+			return fmt.Errorf("host key verification failed for %s: %s", hostname, err.Error())
+		}
+		known = known || len(keyErr.Want) > 0
+	}
+
+	if known {
+		return fmt.Errorf(
+			"host key verification failed for %s: presented %s key %s does not match the expected host key, "+
+				"the connection may have been intercepted",
+			hostname, key.Type(), ssh.FingerprintSHA256(key))
+	}
+
+	if c.policy == hostKeyCheckAcceptNew {
+		return appendKnownHost(c.newHostsPath, hostname, key)
+	}
+
+	return fmt.Errorf(
+		"host key verification failed for %s: host is unknown, presented %s key %s",
+		hostname, key.Type(), ssh.FingerprintSHA256(key))
+}
+
+func appendKnownHost(path string, hostname string, key ssh.PublicKey) error {
+	knownHostsMux.Lock()
+	defer knownHostsMux.Unlock()
+
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return fmt.Errorf("couldn't create directory for known_hosts file: %s", err.Error())
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("couldn't open known_hosts file: %s", err.Error())
+	}
+	defer file.Close()
+
+	line := knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key)
+	_, err = file.WriteString(line + "\n")
+	if err != nil {
+		return fmt.Errorf("couldn't add host key to known_hosts file: %s", err.Error())
+	}
+
+	return nil
+}
+
+func writeTempKnownHosts(content string) (string, error) {
+	file, err := ioutil.TempFile("", "known_hosts")
+	if err != nil {
+		return "", fmt.Errorf("couldn't create temporary known_hosts file: %s", err.Error())
+	}
+	defer file.Close()
+
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+
+	_, err = file.WriteString(content)
+	if err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("couldn't write temporary known_hosts file: %s", err.Error())
+	}
+
+	return file.Name(), nil
+}
@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func TestHostKeyPinnedWhileServerPrefersOtherKey(t *testing.T) {
+	server := newTestSSHServer(t)
+	d := server.resourceData(t, map[string]interface{}{
+		"host_key": string(ssh.MarshalAuthorizedKey(server.hostKey)),
+	})
+
+	client, err := remoteClientFromResourceData(context.Background(), d, "")
+	if err != nil {
+		t.Fatalf("expected the pinned ed25519 key to be accepted, got %s", err.Error())
+	}
+	client.Close()
+}
+
+func TestHostKeyKnownWhileServerPrefersOtherKey(t *testing.T) {
+	server := newTestSSHServer(t)
+	line := knownhosts.Line([]string{knownhosts.Normalize(server.addr)}, server.hostKey)
+	d := server.resourceData(t, map[string]interface{}{
+		"known_hosts": line,
+	})
+
+	client, err := remoteClientFromResourceData(context.Background(), d, "")
+	if err != nil {
+		t.Fatalf("expected the known ed25519 key to be accepted, got %s", err.Error())
+	}
+	client.Close()
+}
+
+func TestHostKeyAlgorithms(t *testing.T) {
+	server := newTestSSHServer(t)
+
+	rsaKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte("ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAAAgQC6ttL4UBq6X/XjVfQ6VuVI1Jx6Qe5gZqmDGy9uBFK+B6kMx5k9uHQ3CvPdVA4V8k/jqzvKQxsI1l6UQ6H2IbGxkEwO2yKkVUZ8OT6zUUYb+3BqBsvZbNvwuJYRRB2vsVd+Lw7D+dK+nwXEF0EbUZdL/7JkNbhSLhx3r0s0YRZj/Q=="))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	tests := []struct {
+		name     string
+		settings hostKeySettings
+		expected []string
+	}{
+		{
+			name:     "pinned ed25519 key",
+			settings: hostKeySettings{hostKey: string(ssh.MarshalAuthorizedKey(server.hostKey))},
+			expected: []string{ssh.KeyAlgoED25519},
+		},
+		{
+			name:     "known rsa key",
+			settings: hostKeySettings{knownHosts: knownhosts.Line([]string{"example.com"}, rsaKey)},
+			expected: []string{ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA},
+		},
+		{
+			name:     "key of other host",
+			settings: hostKeySettings{knownHosts: knownhosts.Line([]string{"other.example.com"}, rsaKey)},
+			expected: nil,
+		},
+		{
+			name:     "certificate authority",
+			settings: hostKeySettings{knownHosts: "@cert-authority *.com " + string(ssh.MarshalAuthorizedKey(server.hostKey))},
+			expected: nil,
+		},
+	}
+
+	for _, test := range tests {
+		_, algorithms, err := test.settings.callback("example.com:22")
+		if err != nil {
+			t.Fatalf("%s: err: %s", test.name, err.Error())
+		}
+		if len(algorithms) != len(test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, algorithms)
+			continue
+		}
+		for i := range algorithms {
+			if algorithms[i] != test.expected[i] {
+				t.Errorf("%s: expected %v, got %v", test.name, test.expected, algorithms)
+				break
+			}
+		}
+	}
+}
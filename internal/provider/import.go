@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+var proxyHopRegexp = regexp.MustCompile(`^[^:@]+:[0-9]+$`)
+
+// parseImportID splits an import ID of the form [proxy_host:proxy_port|...][user@]host:port:path
+// into the resource ID, which lacks the user, the user and the path.
+func parseImportID(importID string) (id string, user string, path string, err error) {
+	segments := strings.Split(importID, "|")
+
+	hops := []string{}
+	for len(segments) > 1 && proxyHopRegexp.MatchString(segments[0]) {
+		hops = append(hops, segments[0])
+		segments = segments[1:]
+	}
+	target := strings.Join(segments, "|")
+
+	at := strings.Index(target, "@")
+	colon := strings.Index(target, ":")
+	if at >= 0 && at < colon {
+		user = target[:at]
+		target = target[at+1:]
+	}
+
+	parts := strings.SplitN(target, ":", 3)
+	if len(parts) != 3 || parts[0] == "" || !proxyHopRegexp.MatchString(parts[0]+":"+parts[1]) || parts[2] == "" {
+		return "", "", "", fmt.Errorf("unexpected import ID %q, expected [proxy_host:proxy_port|...][user@]host:port:path", importID)
+	}
+
+	return strings.Join(append(hops, target), "|"), user, parts[2], nil
+}
+
+// resourceRemoteFileImport adopts an existing file on the host of the provider's connection.
+// Attributes not read by resourceRemoteFileRead are set here.
+func resourceRemoteFileImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	id, user, path, err := parseImportID(d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := meta.(*apiClient).getConnWithDefault(d)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := resourceID(conn, path)
+	if id != expected {
+		return nil, fmt.Errorf("import ID %q doesn't match the connection of the provider, expected %q", d.Id(), expected)
+	}
+	if user != "" && user != conn.Get("conn.0.user").(string) {
+		return nil, fmt.Errorf("import ID %q doesn't match the user %s of the provider's connection", d.Id(), conn.Get("conn.0.user").(string))
+	}
+
+	become, err := becomeFromResourceData(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := meta.(*apiClient).getRemoteClient(ctx, conn)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open remote client: %s", err.Error())
+	}
+	defer meta.(*apiClient).closeRemoteClient(conn)
+
+	file, err := client.LoadFileAttributes(path, become)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read remote file: %s", err.Error())
+	}
+	if !file.Exists {
+		return nil, fmt.Errorf("remote file %s doesn't exist", path)
+	}
+
+	d.SetId(id)
+	d.Set("path", path)
+
+	// Settings that aren't read from the remote host are imported with their defaults, owner and
+	// group are left unset like in a configuration that doesn't manage them
+	for key, attribute := range resourceRemoteFile().Schema {
+		if attribute.Default != nil {
+			d.Set(key, attribute.Default)
+		}
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
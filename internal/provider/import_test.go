@@ -0,0 +1,34 @@
+package provider
+
+import "testing"
+
+func TestParseImportID(t *testing.T) {
+	tests := []struct {
+		importID string
+		id       string
+		user     string
+		path     string
+	}{
+		{"remotehost:22:/tmp/file", "remotehost:22:/tmp/file", "", "/tmp/file"},
+		{"root@remotehost:22:/tmp/file", "remotehost:22:/tmp/file", "root", "/tmp/file"},
+		{"proxy:22|remotehost:1022:/tmp/a:b", "proxy:22|remotehost:1022:/tmp/a:b", "", "/tmp/a:b"},
+		{"proxy1:22|proxy2:22|bob@remotehost:22:/tmp/a|b@c", "proxy1:22|proxy2:22|remotehost:22:/tmp/a|b@c", "bob", "/tmp/a|b@c"},
+	}
+
+	for _, test := range tests {
+		id, user, path, err := parseImportID(test.importID)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %s", test.importID, err.Error())
+		}
+		if id != test.id || user != test.user || path != test.path {
+			t.Errorf("expected %q, %q, %q for %q, got %q, %q, %q", test.id, test.user, test.path, test.importID, id, user, path)
+		}
+	}
+
+	for _, importID := range []string{"/tmp/file", "remotehost:/tmp/file", "remotehost:ssh:/tmp/file", "remotehost:22:"} {
+		_, _, _, err := parseImportID(importID)
+		if err == nil {
+			t.Errorf("expected %q to be rejected", importID)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package provider
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	totpPeriod = 30 * time.Second
+	totpDigits = 6
+)
+
+var (
+	passwordPromptRegexp = regexp.MustCompile(`(?i)password`)
+	codePromptRegexp     = regexp.MustCompile(`(?i)code|otp|token|one-time|verification`)
+)
+
+type keyboardInteractiveRule struct {
+	prompt *regexp.Regexp
+	answer string
+	totp   bool
+}
+
+type keyboardInteractiveResponder struct {
+	rules      []keyboardInteractiveRule
+	password   string
+	totpSecret []byte
+	now        func() time.Time
+}
+
+// keyboardInteractiveFromResourceData returns a keyboard-interactive authentication method when
+// answer rules or a TOTP secret are configured, otherwise nil.
+func keyboardInteractiveFromResourceData(prefixKey func(string) string, d *schema.ResourceData) (ssh.AuthMethod, error) {
+	responder := keyboardInteractiveResponder{
+		password: resourceStringWithDefault(d, prefixKey("password"), ""),
+		now:      time.Now,
+	}
+
+	totpSecret, ok := d.GetOk(prefixKey("totp_secret"))
+	if ok {
+		secret, err := decodeTOTPSecret(totpSecret.(string))
+		if err != nil {
+			return nil, err
+		}
+		responder.totpSecret = secret
+	}
+
+	rules, _ := d.Get(prefixKey("keyboard_interactive")).([]interface{})
+	for i, rule := range rules {
+		rule := rule.(map[string]interface{})
+
+		prompt, err := regexp.Compile(rule["prompt"].(string))
+		if err != nil {
+			return nil, fmt.Errorf("invalid keyboard_interactive prompt: %s", err.Error())
+		}
+		if rule["totp"].(bool) && responder.totpSecret == nil {
+			return nil, fmt.Errorf("keyboard_interactive rule %d answers with a TOTP code, but totp_secret is not set", i)
+		}
+
+		responder.rules = append(responder.rules, keyboardInteractiveRule{
+			prompt: prompt,
+			answer: rule["answer"].(string),
+			totp:   rule["totp"].(bool),
+		})
+	}
+
+	if len(responder.rules) == 0 && responder.totpSecret == nil {
+		return nil, nil
+	}
+
+	return ssh.KeyboardInteractive(responder.challenge), nil
+}
+
+func (r *keyboardInteractiveResponder) challenge(user, instruction string, questions []string, echos []bool) ([]string, error) {
+	answers := make([]string, 0, len(questions))
+	for _, question := range questions {
+		answer, err := r.answer(question)
+		if err != nil {
+			return nil, err
+		}
+		answers = append(answers, answer)
+	}
+	return answers, nil
+}
+
+func (r *keyboardInteractiveResponder) answer(question string) (string, error) {
+	for _, rule := range r.rules {
+		if !rule.prompt.MatchString(question) {
+			continue
+		}
+		if rule.totp {
+			return totpCode(r.totpSecret, r.now()), nil
+		}
+		return rule.answer, nil
+	}
+
+	if r.password != "" && passwordPromptRegexp.MatchString(question) {
+		return r.password, nil
+	}
+	if r.totpSecret != nil && codePromptRegexp.MatchString(question) {
+		return totpCode(r.totpSecret, r.now()), nil
+	}
+
+	return "", fmt.Errorf("no answer configured for keyboard-interactive prompt %q", strings.TrimSpace(question))
+}
+
+func decodeTOTPSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	secret = strings.TrimRight(secret, "=")
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decode totp_secret, expected base32: %s", err.Error())
+	}
+	if len(key) == 0 {
+		return nil, errors.New("totp_secret is empty")
+	}
+	return key, nil
+}
+
+// totpCode generates a time-based one-time password as specified by RFC 6238 with
+// the parameters used by common authenticator apps.
+func totpCode(key []byte, t time.Time) string {
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(t.Unix()/int64(totpPeriod/time.Second)))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulo := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		modulo *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, code%modulo)
+}
@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// Test vectors of RFC 6238 truncated to six digits
+	key, err := decodeTOTPSecret("GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1234567890:  "005924",
+		20000000000: "353130",
+	}
+	for unix, expected := range vectors {
+		code := totpCode(key, time.Unix(unix, 0))
+		if code != expected {
+			t.Errorf("time %d: expected %s, got %s", unix, expected, code)
+		}
+	}
+}
+
+func TestKeyboardInteractiveAnswers(t *testing.T) {
+	key, err := decodeTOTPSecret("gezd gnbv gy3t qojq gezd gnbv gy3t qojq")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	responder := keyboardInteractiveResponder{
+		rules: []keyboardInteractiveRule{
+			{prompt: regexp.MustCompile("^Site:"), answer: "prague"},
+		},
+		password:   "password",
+		totpSecret: key,
+		now:        func() time.Time { return time.Unix(59, 0) },
+	}
+
+	answers, err := responder.challenge("root", "", []string{"Password: ", "Verification code: ", "Site: "}, []bool{false, false, true})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := []string{"password", "287082", "prague"}
+	for i := range expected {
+		if answers[i] != expected[i] {
+			t.Errorf("answer %d: expected %s, got %s", i, expected[i], answers[i])
+		}
+	}
+
+	_, err = responder.challenge("root", "", []string{"Favourite color: "}, []bool{true})
+	if err == nil {
+		t.Error("expected an error for a prompt without answer")
+	}
+}
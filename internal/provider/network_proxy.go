@@ -0,0 +1,153 @@
+package provider
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"golang.org/x/net/proxy"
+)
+
+// networkProxyDialer returns a dialer opening TCP connections through the network proxy at
+// proxyURL, or directly when proxyURL is empty.
+func networkProxyDialer(proxyURL string, timeout time.Duration) (proxy.Dialer, error) {
+	direct := &net.Dialer{Timeout: timeout}
+	if proxyURL == "" {
+		return direct, nil
+	}
+
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse network proxy URL: %s", err.Error())
+	}
+
+	switch u.Scheme {
+	case "socks5", "socks5h":
+		dialer, err := proxy.FromURL(u, direct)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't create network proxy dialer: %s", err.Error())
+		}
+		return dialer, nil
+	case "http", "https":
+		return &httpConnectDialer{proxyURL: u, forward: direct, timeout: timeout}, nil
+	default:
+		return nil, fmt.Errorf("unsupported network proxy scheme %q, expected one of socks5, socks5h, http or https", u.Scheme)
+	}
+}
+
+// dialerFromResourceData returns the dialer used to open the first connection, which is the one
+// to the first proxy_conn host if any, otherwise the one to the conn host.
+func dialerFromResourceData(d *schema.ResourceData, defaultNetworkProxy string, timeout time.Duration) (proxy.Dialer, error) {
+	networkProxy := firstConnectionString(d, "network_proxy", "")
+	proxyCommand := firstConnectionString(d, "proxy_command", "")
+
+	if proxyCommand != "" {
+		if networkProxy != "" {
+			return nil, errors.New("only one of network_proxy and proxy_command can be set")
+		}
+		return &commandDialer{command: proxyCommand}, nil
+	}
+
+	if networkProxy == "" {
+		networkProxy = defaultNetworkProxy
+	}
+	return networkProxyDialer(networkProxy, timeout)
+}
+
+// firstConnectionString returns the value of key from the first proxy_conn, falling back to conn.
+func firstConnectionString(d *schema.ResourceData, key string, defaultValue string) string {
+	if proxyConnectionCount(d) > 0 {
+		value := resourceStringWithDefault(d, fmt.Sprintf("proxy_conn.0.%s", key), "")
+		if value != "" {
+			return value
+		}
+	}
+	return resourceStringWithDefault(d, fmt.Sprintf("conn.0.%s", key), defaultValue)
+}
+
+// httpConnectDialer tunnels TCP connections through an HTTP proxy using the CONNECT method.
+type httpConnectDialer struct {
+	proxyURL *url.URL
+	forward  proxy.Dialer
+	timeout  time.Duration
+}
+
+func (d *httpConnectDialer) Dial(network, addr string) (net.Conn, error) {
+	proxyAddr := d.proxyURL.Host
+	if d.proxyURL.Port() == "" {
+		port := "80"
+		if d.proxyURL.Scheme == "https" {
+			port = "443"
+		}
+		proxyAddr = net.JoinHostPort(d.proxyURL.Hostname(), port)
+	}
+
+	conn, err := d.forward.Dial("tcp", proxyAddr)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't connect to network proxy: %s", err.Error())
+	}
+
+	if d.proxyURL.Scheme == "https" {
+		conn = tls.Client(conn, &tls.Config{ServerName: d.proxyURL.Hostname()})
+	}
+
+	if d.timeout > 0 {
+		conn.SetDeadline(time.Now().Add(d.timeout))
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: http.Header{},
+	}
+	if d.proxyURL.User != nil {
+		password, _ := d.proxyURL.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(d.proxyURL.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+
+	err = req.Write(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("couldn't send request to network proxy: %s", err.Error())
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("couldn't read response of network proxy: %s", err.Error())
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("network proxy refused to connect to %s: %s", addr, resp.Status)
+	}
+
+	conn.SetDeadline(time.Time{})
+
+	if reader.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn returns data read ahead while parsing the proxy response before reading
+// from the connection itself.
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
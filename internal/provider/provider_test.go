@@ -2,10 +2,21 @@ package provider
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
 )
 
 // providerFactories are used to instantiate a provider during acceptance testing.
@@ -95,6 +106,36 @@ var providerFactories = map[string]func() (*schema.Provider, error){
 		}
 		return provider, nil
 	},
+	"remotehost2-through-remotehost2-and-remotehost": func() (*schema.Provider, error) {
+		provider := New("dev")()
+		configureProvider := provider.ConfigureContextFunc
+		provider.ConfigureContextFunc = func(c context.Context, rd *schema.ResourceData) (interface{}, diag.Diagnostics) {
+			rd.Set("conn", []interface{}{
+				map[string]interface{}{
+					"host":     "remotehost2",
+					"user":     "root",
+					"password": "password",
+					"port":     1022,
+				},
+			})
+			rd.Set("proxy_conn", []interface{}{
+				map[string]interface{}{
+					"host":     "remotehost2",
+					"user":     "root",
+					"password": "password",
+					"port":     22,
+				},
+				map[string]interface{}{
+					"host":     "remotehost",
+					"user":     "root",
+					"password": "password",
+					"port":     22,
+				},
+			})
+			return configureProvider(c, rd)
+		}
+		return provider, nil
+	},
 }
 
 func TestProvider(t *testing.T) {
@@ -103,6 +144,270 @@ func TestProvider(t *testing.T) {
 	}
 }
 
+// testSSHServer is an in-process SSH server accepting the password "password" and serving the
+// local file system over SFTP. Commands aren't run, they are recorded and exit with status 2,
+// like getent does for a missing entry. Like a default OpenSSH server, it has an ECDSA and an
+// ed25519 host key.
+type testSSHServer struct {
+	addr     string
+	hostKey  ssh.PublicKey
+	mux      sync.Mutex
+	conns    []net.Conn
+	commands []string
+}
+
+func newTestSSHServer(t *testing.T) *testSSHServer {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	signer, err := ssh.NewSignerFromKey(private)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	ecdsaPrivate, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	ecdsaSigner, err := ssh.NewSignerFromKey(ecdsaPrivate)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(meta ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if string(password) != "password" {
+				return nil, errors.New("wrong password")
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(ecdsaSigner)
+	config.AddHostKey(signer)
+
+	server := &testSSHServer{hostKey: signer.PublicKey()}
+	server.addr = listen(t, func(conn net.Conn) {
+		server.mux.Lock()
+		server.conns = append(server.conns, conn)
+		server.mux.Unlock()
+
+		_, channels, requests, err := ssh.NewServerConn(conn, config)
+		if err != nil {
+			conn.Close()
+			return
+		}
+		go ssh.DiscardRequests(requests)
+		for newChannel := range channels {
+			if newChannel.ChannelType() != "session" {
+				newChannel.Reject(ssh.UnknownChannelType, "only sessions are supported")
+				continue
+			}
+			channel, requests, err := newChannel.Accept()
+			if err != nil {
+				continue
+			}
+			go server.serveSession(channel, requests)
+		}
+	})
+	t.Cleanup(server.closeConnections)
+
+	return server
+}
+
+// serveSession serves SFTP on channel once the subsystem is requested, or records a command.
+func (s *testSSHServer) serveSession(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+
+	for request := range requests {
+		// The payloads are the length prefixed name of the subsystem or the command
+		if request.Type == "exec" && len(request.Payload) > 4 {
+			s.mux.Lock()
+			s.commands = append(s.commands, string(request.Payload[4:]))
+			s.mux.Unlock()
+
+			request.Reply(true, nil)
+			channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{2}))
+			return
+		}
+		ok := request.Type == "subsystem" && len(request.Payload) > 4 && string(request.Payload[4:]) == "sftp"
+		request.Reply(ok, nil)
+		if !ok {
+			continue
+		}
+
+		go ssh.DiscardRequests(requests)
+		server, err := sftp.NewServer(channel)
+		if err != nil {
+			return
+		}
+		server.Serve()
+		return
+	}
+}
+
+// connectionCount returns the number of connections accepted so far.
+func (s *testSSHServer) connectionCount() int {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return len(s.conns)
+}
+
+// commandCount returns the number of commands run so far.
+func (s *testSSHServer) commandCount() int {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return len(s.commands)
+}
+
+// closeConnections drops all connections, like a server going away would.
+func (s *testSSHServer) closeConnections() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+}
+
+// resourceData returns a data source connecting to the server with settings added to conn.
+func (s *testSSHServer) resourceData(t *testing.T, settings map[string]interface{}) *schema.ResourceData {
+	host, port, err := net.SplitHostPort(s.addr)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	portNumber, err := strconv.Atoi(port)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	conn := map[string]interface{}{
+		"host":     host,
+		"port":     portNumber,
+		"user":     "root",
+		"password": "password",
+	}
+	for key, value := range settings {
+		conn[key] = value
+	}
+
+	return schema.TestResourceDataRaw(t, dataSourceRemoteFile().Schema, map[string]interface{}{
+		"conn": []interface{}{conn},
+		"path": "/tmp/file",
+	})
+}
+
+func newTestAPIClient(t *testing.T, idleTimeout time.Duration) *apiClient {
+	client := &apiClient{
+		mux:           &sync.Mutex{},
+		connections:   map[string]*remoteConnection{},
+		totalSessions: newSemaphore(0),
+		idleTimeout:   idleTimeout,
+	}
+	t.Cleanup(client.closeConnections)
+	return client
+}
+
+// waitUntil polls condition until it holds or a few seconds have passed.
+func waitUntil(condition func() bool) bool {
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return true
+}
+
+func TestRemoteClientReusedWithinIdleTimeout(t *testing.T) {
+	server := newTestSSHServer(t)
+	d := server.resourceData(t, nil)
+	c := newTestAPIClient(t, time.Minute)
+
+	first, err := c.getRemoteClient(context.Background(), d)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	c.closeRemoteClient(d)
+
+	second, err := c.getRemoteClient(context.Background(), d)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	c.closeRemoteClient(d)
+
+	if second != first {
+		t.Error("expected the idle client to be reused")
+	}
+	if !first.Alive() {
+		t.Error("expected the idle client to stay open")
+	}
+	if server.connectionCount() != 1 {
+		t.Errorf("expected 1 connection, got %d", server.connectionCount())
+	}
+}
+
+func TestRemoteClientClosedAfterIdleTimeout(t *testing.T) {
+	server := newTestSSHServer(t)
+	d := server.resourceData(t, nil)
+	c := newTestAPIClient(t, 50*time.Millisecond)
+
+	first, err := c.getRemoteClient(context.Background(), d)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	c.closeRemoteClient(d)
+
+	if !waitUntil(func() bool { return !first.Alive() }) {
+		t.Fatal("expected the client to be closed after the idle timeout")
+	}
+
+	second, err := c.getRemoteClient(context.Background(), d)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	c.closeRemoteClient(d)
+
+	if second == first {
+		t.Error("expected a new client after the idle timeout")
+	}
+	if server.connectionCount() != 2 {
+		t.Errorf("expected 2 connections, got %d", server.connectionCount())
+	}
+}
+
+func TestRemoteClientRedialedAfterTransportDies(t *testing.T) {
+	server := newTestSSHServer(t)
+	d := server.resourceData(t, nil)
+	c := newTestAPIClient(t, time.Minute)
+
+	first, err := c.getRemoteClient(context.Background(), d)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	c.closeRemoteClient(d)
+
+	server.closeConnections()
+	if !waitUntil(func() bool { return !first.Alive() }) {
+		t.Fatal("expected the client to notice the closed connection")
+	}
+
+	second, err := c.getRemoteClient(context.Background(), d)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	c.closeRemoteClient(d)
+
+	if second == first {
+		t.Error("expected the broken client to be evicted")
+	}
+	if !second.Alive() {
+		t.Error("expected the new client to be open")
+	}
+	if server.connectionCount() != 2 {
+		t.Errorf("expected 2 connections, got %d", server.connectionCount())
+	}
+}
+
 func testAccPreCheck(t *testing.T) {
 	// You can add code here to run prior to any test case execution, for example assertions
 	// about the appropriate environment variables being set are common to see in a pre-check
@@ -0,0 +1,154 @@
+package provider
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// commandDialer establishes connections over stdin and stdout of a local command, like
+// ProxyCommand of OpenSSH.
+type commandDialer struct {
+	command string
+}
+
+func (d *commandDialer) Dial(network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	command := strings.NewReplacer("%h", host, "%p", port, "%%", "%").Replace(d.command)
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &commandConn{
+		cmd:    cmd,
+		stdin:  stdin,
+		stdout: stdout,
+		addr:   commandAddr(command),
+		remoteAddr: dialedAddr{
+			network: network,
+			addr:    addr,
+		},
+	}
+	cmd.Stderr = &conn.stderr
+
+	err = cmd.Start()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't start proxy command: %s", err.Error())
+	}
+
+	return conn, nil
+}
+
+type commandConn struct {
+	cmd        *exec.Cmd
+	stdin      io.WriteCloser
+	stdout     io.ReadCloser
+	stderr     bytes.Buffer
+	addr       commandAddr
+	remoteAddr dialedAddr
+	waitOnce   sync.Once
+	waitErr    error
+}
+
+func (c *commandConn) wait() error {
+	c.waitOnce.Do(func() {
+		c.waitErr = c.cmd.Wait()
+	})
+	return c.waitErr
+}
+
+func (c *commandConn) Read(b []byte) (int, error) {
+	n, err := c.stdout.Read(b)
+	if errors.Is(err, io.EOF) {
+		waitErr := c.wait()
+		stderr := strings.TrimSpace(c.stderr.String())
+		if waitErr != nil {
+			return n, fmt.Errorf("proxy command exited: %s %s", waitErr.Error(), stderr)
+		}
+		if stderr != "" {
+			return n, fmt.Errorf("proxy command closed the connection: %s", stderr)
+		}
+	}
+	return n, err
+}
+
+func (c *commandConn) Write(b []byte) (int, error) {
+	return c.stdin.Write(b)
+}
+
+func (c *commandConn) Close() error {
+	c.stdin.Close()
+	c.cmd.Process.Kill()
+	c.wait()
+	return nil
+}
+
+func (c *commandConn) LocalAddr() net.Addr {
+	return c.addr
+}
+
+// RemoteAddr returns the dialed host and port, which host key callbacks like those of
+// knownhosts expect, rather than the command.
+func (c *commandConn) RemoteAddr() net.Addr {
+	return c.remoteAddr
+}
+
+func (c *commandConn) SetDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *commandConn) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *commandConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+type commandAddr string
+
+func (a commandAddr) Network() string {
+	return "command"
+}
+
+func (a commandAddr) String() string {
+	return string(a)
+}
+
+// dialedAddr is the address a commandDialer was asked to connect to.
+type dialedAddr struct {
+	network string
+	addr    string
+}
+
+func (a dialedAddr) Network() string {
+	return a.network
+}
+
+func (a dialedAddr) String() string {
+	return a.addr
+}
@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func TestCommandDialer(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	dialer := &commandDialer{command: "test %h:%p = remotehost:22 && cat"}
+	conn, err := dialer.Dial("tcp", "remotehost:22")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("ping"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	response := make([]byte, 4)
+	_, err = io.ReadFull(conn, response)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if string(response) != "ping" {
+		t.Errorf("expected ping, got %s", response)
+	}
+}
+
+func TestCommandDialerFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	dialer := &commandDialer{command: "echo 'no route to %h' >&2; exit 3"}
+	conn, err := dialer.Dial("tcp", "remotehost:22")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer conn.Close()
+
+	_, err = io.ReadAll(conn)
+	if err == nil || !strings.Contains(err.Error(), "no route to remotehost") {
+		t.Errorf("expected an error with the output of the command, got %v", err)
+	}
+}
+
+func TestCommandDialerRemoteAddr(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	dialer := &commandDialer{command: "cat"}
+	conn, err := dialer.Dial("tcp", "remotehost:1022")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer conn.Close()
+
+	public, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	key, err := ssh.NewPublicKey(public)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	knownHostsPath := filepath.Join(t.TempDir(), "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize("remotehost:1022")}, key)
+	err = os.WriteFile(knownHostsPath, []byte(line+"\n"), 0600)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	callback, err := knownhosts.New(knownHostsPath)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	err = callback("remotehost:1022", conn.RemoteAddr(), key)
+	if err != nil {
+		t.Errorf("expected the known host key to be accepted, got %s", err.Error())
+	}
+}
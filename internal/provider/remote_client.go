@@ -2,12 +2,24 @@ package provider
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
-	"github.com/bramvdbogaerde/go-scp"
 	"github.com/pkg/sftp"
 	"golang.org/x/crypto/ssh"
+	"golang.org/x/net/proxy"
+)
+
+const (
+	unixFileTypeMask      = 0170000
+	unixFileTypeRegular   = 0100000
+	unixFileTypeDirectory = 0040000
 )
 
 type Error struct {
@@ -21,149 +33,258 @@ func (e Error) Error() string {
 	return fmt.Sprintf("`%s`\n  %s\n  %s", e.cmd, e.err, stderr)
 }
 
-func run(s *ssh.Session, cmd string) error {
-	var b bytes.Buffer
-	s.Stderr = &b
-	err := s.Run(cmd)
+type RemoteClient struct {
+	sshClient    *ssh.Client
+	proxyClients []*ssh.Client
+	done         chan struct{}
+	sftpMux      sync.Mutex
+	sftpClient   *sftp.Client
+	users        *userDatabase
+}
 
-	if err != nil {
-		return Error{
-			cmd:    cmd,
-			err:    err,
-			stderr: b.Bytes(),
+func newRemoteClient(sshClient *ssh.Client, proxyClients []*ssh.Client) *RemoteClient {
+	client := &RemoteClient{
+		sshClient:    sshClient,
+		proxyClients: proxyClients,
+		done:         make(chan struct{}),
+		users:        newUserDatabase(),
+	}
+
+	go func() {
+		sshClient.Wait()
+		close(client.done)
+	}()
+
+	return client
+}
+
+// Alive reports whether the connection to the remote server is still open.
+func (c *RemoteClient) Alive() bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// keepalive sends a keepalive request every interval and closes the client when one fails or
+// isn't answered before the next one is due.
+func (c *RemoteClient) keepalive(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+		}
+
+		reply := make(chan error, 1)
+		go func() {
+			_, _, err := c.sshClient.SendRequest("keepalive@openssh.com", true, nil)
+			reply <- err
+		}()
+
+		select {
+		case <-c.done:
+			return
+		case err := <-reply:
+			if err != nil {
+				c.Close()
+				return
+			}
+		case <-time.After(interval):
+			c.Close()
+			return
 		}
 	}
-	return nil
 }
 
-type RemoteClient struct {
-	sshClient *ssh.Client
+// WriteFileFrom streams the content of reader to the file at path without buffering it.
+func (c *RemoteClient) WriteFileFrom(reader io.Reader, path string, become *Become) error {
+	if become != nil {
+		return c.WriteFileShellFrom(reader, path, become)
+	}
+	return c.WriteFileSFTPFrom(reader, path)
 }
 
-func (c *RemoteClient) WriteFile(content string, path string, permissions string, sudo bool) error {
-	if sudo {
-		return c.WriteFileShell(content, path)
+func (c *RemoteClient) WriteFileSFTPFrom(reader io.Reader, path string) error {
+	sftpClient, err := c.GetSFTPClient()
+	if err != nil {
+		return err
+	}
+
+	// Permissions of an existing file are left alone until the caller sets the final ones
+	_, err = sftpClient.Lstat(path)
+	created := errors.Is(err, os.ErrNotExist)
+	if err != nil && !created {
+		return err
+	}
+
+	flags := os.O_WRONLY | os.O_TRUNC
+	if created {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_EXCL
 	}
-	return c.WriteFileSCP(content, path, permissions)
+	file, err := sftpClient.OpenFile(path, flags)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// Restrict access to a new file before any content is written
+	if created {
+		err = file.Chmod(0600)
+		if err != nil {
+			return fmt.Errorf("couldn't restrict permissions of file: %s", err.Error())
+		}
+	}
+
+	_, err = io.Copy(file, reader)
+	if err != nil {
+		return err
+	}
+	return file.Close()
 }
 
-func (c *RemoteClient) WriteFileSCP(content string, path string, permissions string) error {
-	scpClient, err := c.GetSCPClient()
+func (c *RemoteClient) WriteFileShellFrom(reader io.Reader, path string, become *Become) error {
+	// umask only restricts access to a new file, an existing one keeps its permissions
+	cmd := fmt.Sprintf("umask 077 && cat > %s", shellQuote(path))
+	_, err := c.runCommand(cmd, reader, become)
+	return err
+}
+
+func (c *RemoteClient) ChmodFile(path string, permissions string, become *Become) error {
+	err := validatePermissions(permissions)
 	if err != nil {
 		return err
 	}
-	defer scpClient.Close()
 
-	return scpClient.CopyFile(strings.NewReader(content), path, permissions)
+	if become == nil {
+		return c.ChmodFileSFTP(path, permissions)
+	}
+
+	cmd := shellCommand("chmod", permissions, "--", path)
+	_, err = c.runCommand(cmd, nil, become)
+	return err
 }
 
-func (c *RemoteClient) WriteFileShell(content string, path string) error {
-	sshClient := c.GetSSHClient()
+func (c *RemoteClient) ChmodFileSFTP(path string, permissions string) error {
+	mode, err := strconv.ParseUint(permissions, 8, 32)
+	if err != nil {
+		return err
+	}
 
-	session, err := sshClient.NewSession()
+	sftpClient, err := c.GetSFTPClient()
 	if err != nil {
 		return err
 	}
-	defer session.Close()
 
-	stdin, err := session.StdinPipe()
+	return sftpClient.Chmod(path, os.FileMode(mode))
+}
+
+func (c *RemoteClient) ChgrpFile(path string, group string, become *Become) error {
+	err := validateGroup(group)
 	if err != nil {
 		return err
 	}
 
-	go func() {
-		stdin.Write([]byte(content))
-		stdin.Close()
-	}()
+	if become == nil {
+		return c.ChgrpFileSFTP(path, group)
+	}
 
-	cmd := fmt.Sprintf("cat /dev/stdin | sudo tee %s", path)
-	return run(session, cmd)
+	cmd := shellCommand("chgrp", group, "--", path)
+	_, err = c.runCommand(cmd, nil, become)
+	return err
 }
 
-func (c *RemoteClient) ChmodFile(path string, permissions string, sudo bool) error {
-	sshClient := c.GetSSHClient()
+func (c *RemoteClient) ChgrpFileSFTP(path string, group string) error {
+	gid, err := c.groupID(group)
+	if err != nil {
+		return err
+	}
 
-	session, err := sshClient.NewSession()
+	stat, err := c.StatFileSFTP(path)
 	if err != nil {
 		return err
 	}
-	defer session.Close()
 
-	cmd := fmt.Sprintf("chmod %s %s", permissions, path)
-	if sudo {
-		cmd = fmt.Sprintf("sudo %s", cmd)
+	sftpClient, err := c.GetSFTPClient()
+	if err != nil {
+		return err
 	}
-	return run(session, cmd)
-}
 
-func (c *RemoteClient) ChgrpFile(path string, group string, sudo bool) error {
-	sshClient := c.GetSSHClient()
+	return sftpClient.Chown(path, int(stat.UID), gid)
+}
 
-	session, err := sshClient.NewSession()
+func (c *RemoteClient) ChownFile(path string, owner string, become *Become) error {
+	err := validateOwner(owner)
 	if err != nil {
 		return err
 	}
-	defer session.Close()
 
-	cmd := fmt.Sprintf("chgrp %s %s", group, path)
-	if sudo {
-		cmd = fmt.Sprintf("sudo %s", cmd)
+	if become == nil {
+		return c.ChownFileSFTP(path, owner)
 	}
 
-	return run(session, cmd)
+	cmd := shellCommand("chown", owner, "--", path)
+	_, err = c.runCommand(cmd, nil, become)
+	return err
 }
 
-func (c *RemoteClient) ChownFile(path string, owner string, sudo bool) error {
-	sshClient := c.GetSSHClient()
+func (c *RemoteClient) ChownFileSFTP(path string, owner string) error {
+	uid, err := c.userID(owner)
+	if err != nil {
+		return err
+	}
 
-	session, err := sshClient.NewSession()
+	stat, err := c.StatFileSFTP(path)
 	if err != nil {
 		return err
 	}
-	defer session.Close()
 
-	cmd := fmt.Sprintf("chown %s %s", owner, path)
-	if sudo {
-		cmd = fmt.Sprintf("sudo %s", cmd)
+	sftpClient, err := c.GetSFTPClient()
+	if err != nil {
+		return err
 	}
-	return run(session, cmd)
+
+	return sftpClient.Chown(path, uid, int(stat.GID))
 }
 
-func (c *RemoteClient) FileExists(path string, sudo bool) (bool, error) {
-	sshClient := c.GetSSHClient()
+func (c *RemoteClient) FileExists(path string, become *Become) (bool, error) {
+	if become == nil {
+		return c.FileExistsSFTP(path)
+	}
+
+	cmd := shellCommand("test", "-f", path)
+	_, err := c.runCommand(cmd, nil, become)
 
-	session, err := sshClient.NewSession()
 	if err != nil {
+		cmd := shellCommand("test", "!", "-f", path)
+		_, err := c.runCommand(cmd, nil, become)
 		return false, err
 	}
-	defer session.Close()
 
-	cmd := fmt.Sprintf("test -f %s", path)
-	if sudo {
-		cmd = fmt.Sprintf("sudo %s", cmd)
-	}
-	err = run(session, cmd)
+	return true, nil
+}
 
+func (c *RemoteClient) FileExistsSFTP(path string) (bool, error) {
+	stat, err := c.StatFileSFTP(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
 	if err != nil {
-		session2, err := sshClient.NewSession()
-		if err != nil {
-			return false, err
-		}
-		defer session2.Close()
-
-		cmd := fmt.Sprintf("test ! -f %s", path)
-		if sudo {
-			cmd = fmt.Sprintf("sudo %s", cmd)
-		}
-		return false, session2.Run(cmd)
+		return false, err
 	}
 
-	return true, nil
+	// Like `test -f`, only regular files count
+	return stat.Mode&unixFileTypeMask == unixFileTypeRegular, nil
 }
 
-func (c *RemoteClient) ReadFile(path string, sudo bool) (string, error) {
-	if sudo {
-		return c.ReadFileShell(path)
+func (c *RemoteClient) ReadFile(path string, become *Become) (string, error) {
+	if become != nil {
+		return c.ReadFileShell(path, become)
 	}
 	return c.ReadFileSFTP(path)
 }
@@ -173,7 +294,6 @@ func (c *RemoteClient) ReadFileSFTP(path string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	defer sftpClient.Close()
 
 	file, err := sftpClient.Open(path)
 	if err != nil {
@@ -190,17 +310,9 @@ func (c *RemoteClient) ReadFileSFTP(path string) (string, error) {
 	return content.String(), nil
 }
 
-func (c *RemoteClient) ReadFileShell(path string) (string, error) {
-	sshClient := c.GetSSHClient()
-
-	session, err := sshClient.NewSession()
-	if err != nil {
-		return "", err
-	}
-	defer session.Close()
-
-	cmd := fmt.Sprintf("sudo cat %s", path)
-	content, err := session.Output(cmd)
+func (c *RemoteClient) ReadFileShell(path string, become *Become) (string, error) {
+	cmd := shellCommand("cat", "--", path)
+	content, err := c.runCommand(cmd, nil, become)
 	if err != nil {
 		return "", err
 	}
@@ -208,72 +320,174 @@ func (c *RemoteClient) ReadFileShell(path string) (string, error) {
 	return string(content), nil
 }
 
-func (c *RemoteClient) ReadFilePermissions(path string, sudo bool) (string, error) {
-	sshClient := c.GetSSHClient()
+// RemoteFile is the content and attributes of a file on the remote host.
+type RemoteFile struct {
+	Exists      bool
+	Content     string
+	Size        int64
+	Permissions string
+	Owner       string
+	Group       string
+	OwnerName   string
+	GroupName   string
+}
+
+// LoadFile reads the content and attributes of the file at path at once, using a single SFTP
+// file handle or a single command.
+func (c *RemoteClient) LoadFile(path string, become *Become) (*RemoteFile, error) {
+	if become != nil {
+		return c.LoadFileShell(path, true, become)
+	}
+	return c.LoadFileSFTP(path, true)
+}
 
-	session, err := sshClient.NewSession()
+// LoadFileAttributes is like LoadFile but leaves out the content, e.g. for large files.
+func (c *RemoteClient) LoadFileAttributes(path string, become *Become) (*RemoteFile, error) {
+	if become != nil {
+		return c.LoadFileShell(path, false, become)
+	}
+	return c.LoadFileSFTP(path, false)
+}
+
+func (c *RemoteClient) LoadFileSFTP(path string, withContent bool) (*RemoteFile, error) {
+	sftpClient, err := c.GetSFTPClient()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	defer session.Close()
 
-	cmd := fmt.Sprintf("stat -c %%a %s", path)
-	if sudo {
-		cmd = fmt.Sprintf("sudo %s", cmd)
+	handle, err := sftpClient.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &RemoteFile{Exists: false}, nil
 	}
-	output, err := session.Output(cmd)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	defer handle.Close()
 
-	permissions := strings.ReplaceAll(string(output), "\n", "")
-	if len(permissions) > 0 && len(permissions) < 4 {
-		permissions = fmt.Sprintf("0%s", permissions)
+	info, err := handle.Stat()
+	if err != nil {
+		return nil, err
+	}
+	stat, ok := info.Sys().(*sftp.FileStat)
+	if !ok {
+		return nil, fmt.Errorf("couldn't read attributes of %s", path)
+	}
+	if stat.Mode&unixFileTypeMask != unixFileTypeRegular {
+		return &RemoteFile{Exists: false}, nil
+	}
+
+	content := bytes.Buffer{}
+	if withContent {
+		_, err = handle.WriteTo(&content)
+		if err != nil {
+			return nil, err
+		}
 	}
-	return permissions, nil
-}
 
-func (c *RemoteClient) ReadFileOwner(path string, sudo bool) (string, error) {
-	return c.StatFile(path, "u", sudo)
+	file := c.remoteFileFromStat(stat)
+	file.Content = content.String()
+	return file, nil
 }
 
-func (c *RemoteClient) ReadFileGroup(path string, sudo bool) (string, error) {
-	return c.StatFile(path, "g", sudo)
+func (c *RemoteClient) remoteFileFromStat(stat *sftp.FileStat) *RemoteFile {
+	return &RemoteFile{
+		Exists:      true,
+		Size:        int64(stat.Size),
+		Permissions: fmt.Sprintf("%04o", stat.Mode&07777),
+		Owner:       strconv.FormatUint(uint64(stat.UID), 10),
+		Group:       strconv.FormatUint(uint64(stat.GID), 10),
+		OwnerName:   c.userName(stat.UID),
+		GroupName:   c.groupName(stat.GID),
+	}
 }
 
-func (c *RemoteClient) ReadFileOwnerName(path string, sudo bool) (string, error) {
-	return c.StatFile(path, "U", sudo)
+// LoadFileShell prints the attributes of the file on the first line of the output followed by
+// its content.
+func (c *RemoteClient) LoadFileShell(path string, withContent bool, become *Become) (*RemoteFile, error) {
+	read := statShellCommand(path)
+	if withContent {
+		read = fmt.Sprintf("%s && %s", read, shellCommand("cat", "--", path))
+	}
+	cmd := fmt.Sprintf("if %s; then %s; else echo missing; fi", shellCommand("test", "-f", path), read)
+	output, err := c.runCommand(cmd, nil, become)
+	if err != nil {
+		return nil, err
+	}
+
+	header := string(output)
+	content := ""
+	newline := bytes.IndexByte(output, '\n')
+	if newline >= 0 {
+		header = string(output[:newline])
+		content = string(output[newline+1:])
+	}
+
+	file, err := parseStatOutput(path, header)
+	if err != nil {
+		return nil, err
+	}
+	file.Content = content
+	return file, nil
 }
 
-func (c *RemoteClient) ReadFileGroupName(path string, sudo bool) (string, error) {
-	return c.StatFile(path, "G", sudo)
+// statShellCommand prints the attributes of path in the format read by parseStatOutput.
+func statShellCommand(path string) string {
+	return shellCommand("stat", "-L", "-c", "exists %a %u %g %U %G %s", "--", path)
 }
 
-func (c *RemoteClient) StatFile(path string, char string, sudo bool) (string, error) {
-	sshClient := c.GetSSHClient()
+// parseStatOutput parses the output of statShellCommand, or "missing" when there is no file.
+func parseStatOutput(path string, header string) (*RemoteFile, error) {
+	fields := strings.Fields(header)
+	if len(fields) == 1 && fields[0] == "missing" {
+		return &RemoteFile{Exists: false}, nil
+	}
+	if len(fields) != 7 || fields[0] != "exists" {
+		return nil, fmt.Errorf("unexpected attributes of %s: %q", path, header)
+	}
 
-	session, err := sshClient.NewSession()
+	size, err := strconv.ParseInt(fields[6], 10, 64)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("unexpected size of %s: %s", path, err.Error())
 	}
-	defer session.Close()
 
-	cmd := fmt.Sprintf("stat -c %%%s %s", char, path)
-	if sudo {
-		cmd = fmt.Sprintf("sudo %s", cmd)
+	permissions := fields[1]
+	if len(permissions) < 4 {
+		permissions = fmt.Sprintf("0%s", permissions)
 	}
-	output, err := session.Output(cmd)
+
+	return &RemoteFile{
+		Exists:      true,
+		Size:        size,
+		Permissions: permissions,
+		Owner:       fields[2],
+		Group:       fields[3],
+		OwnerName:   fields[4],
+		GroupName:   fields[5],
+	}, nil
+}
+
+// StatFileSFTP returns the attributes of the file at path, following symbolic links.
+func (c *RemoteClient) StatFileSFTP(path string) (*sftp.FileStat, error) {
+	sftpClient, err := c.GetSFTPClient()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	group := strings.ReplaceAll(string(output), "\n", "")
-	return group, nil
+	info, err := sftpClient.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, ok := info.Sys().(*sftp.FileStat)
+	if !ok {
+		return nil, fmt.Errorf("couldn't read attributes of %s", path)
+	}
+	return stat, nil
 }
 
-func (c *RemoteClient) DeleteFile(path string, sudo bool) error {
-	if sudo {
-		return c.DeleteFileShell(path)
+func (c *RemoteClient) DeleteFile(path string, become *Become) error {
+	if become != nil {
+		return c.DeleteFileShell(path, become)
 	}
 	return c.DeleteFileSFTP(path)
 }
@@ -283,69 +497,117 @@ func (c *RemoteClient) DeleteFileSFTP(path string) error {
 	if err != nil {
 		return err
 	}
-	defer sftpClient.Close()
 
 	return sftpClient.Remove(path)
 }
 
-func (c *RemoteClient) DeleteFileShell(path string) error {
-	sshClient := c.GetSSHClient()
+func (c *RemoteClient) DeleteFileShell(path string, become *Become) error {
+	cmd := shellCommand("rm", "--", path)
+	_, err := c.runCommand(cmd, nil, become)
+	return err
+}
 
-	session, err := sshClient.NewSession()
+func NewRemoteClient(host string, clientConfig *ssh.ClientConfig, dialer proxy.Dialer) (*RemoteClient, error) {
+	client, err := dial(dialer, host, clientConfig)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("couldn't establish a connection to the remote server: %s", err.Error())
 	}
-	defer session.Close()
 
-	cmd := fmt.Sprintf("sudo rm %s", path)
-	return run(session, cmd)
+	return newRemoteClient(client, nil), nil
 }
 
-func NewRemoteClient(host string, clientConfig *ssh.ClientConfig) (*RemoteClient, error) {
-	client, err := ssh.Dial("tcp", host, clientConfig)
+func NewRemoteProxyClient(host string, clientConfig *ssh.ClientConfig, proxyHosts []string, proxyClientConfigs []*ssh.ClientConfig, dialer proxy.Dialer) (*RemoteClient, error) {
+	proxyClients := []*ssh.Client{}
+	closeProxyClients := func() {
+		for i := len(proxyClients) - 1; i >= 0; i-- {
+			proxyClients[i].Close()
+		}
+	}
+
+	for i, proxyHost := range proxyHosts {
+		var proxyClient *ssh.Client
+		var err error
+		if i == 0 {
+			proxyClient, err = dial(dialer, proxyHost, proxyClientConfigs[i])
+		} else {
+			proxyClient, err = dialThrough(proxyClients[i-1], proxyHost, proxyClientConfigs[i])
+		}
+		if err != nil {
+			closeProxyClients()
+			return nil, fmt.Errorf("couldn't establish a connection to the proxy server %s: %s", proxyHost, err.Error())
+		}
+		proxyClients = append(proxyClients, proxyClient)
+	}
+
+	client, err := dialThrough(proxyClients[len(proxyClients)-1], host, clientConfig)
 	if err != nil {
+		closeProxyClients()
 		return nil, fmt.Errorf("couldn't establish a connection to the remote server: %s", err.Error())
 	}
 
-	return &RemoteClient{
-		sshClient: client,
-	}, nil
+	return newRemoteClient(client, proxyClients), nil
 }
 
-func NewRemoteProxyClient(host string, clientConfig *ssh.ClientConfig, proxyHost string, proxyClientConfig *ssh.ClientConfig) (*RemoteClient, error) {
-	proxyClient, err := ssh.Dial("tcp", proxyHost, proxyClientConfig)
+func dial(dialer proxy.Dialer, host string, clientConfig *ssh.ClientConfig) (*ssh.Client, error) {
+	conn, err := dialer.Dial("tcp", host)
 	if err != nil {
-		return nil, fmt.Errorf("couldn't establish a connection to the remote server: %s", err.Error())
+		return nil, err
+	}
+
+	ncc, chans, reqs, err := ssh.NewClientConn(conn, host, clientConfig)
+	if err != nil {
+		conn.Close()
+		return nil, err
 	}
 
+	return ssh.NewClient(ncc, chans, reqs), nil
+}
+
+func dialThrough(proxyClient *ssh.Client, host string, clientConfig *ssh.ClientConfig) (*ssh.Client, error) {
 	conn, err := proxyClient.Dial("tcp", host)
 	if err != nil {
-		return nil, fmt.Errorf("couldn't establish a connection to the remote server: %s", err.Error())
+		return nil, err
 	}
 
 	ncc, chans, reqs, err := ssh.NewClientConn(conn, host, clientConfig)
 	if err != nil {
+		conn.Close()
 		return nil, err
 	}
-	client := ssh.NewClient(ncc, chans, reqs)
 
-	return &RemoteClient{
-		sshClient: client,
-	}, nil
+	return ssh.NewClient(ncc, chans, reqs), nil
 }
 
 func (c *RemoteClient) Close() error {
-	return c.sshClient.Close()
+	c.sftpMux.Lock()
+	if c.sftpClient != nil {
+		c.sftpClient.Close()
+		c.sftpClient = nil
+	}
+	c.sftpMux.Unlock()
+
+	err := c.sshClient.Close()
+	for i := len(c.proxyClients) - 1; i >= 0; i-- {
+		c.proxyClients[i].Close()
+	}
+	return err
 }
 
 func (c *RemoteClient) GetSSHClient() *ssh.Client {
 	return c.sshClient
 }
 
-func (c *RemoteClient) GetSCPClient() (scp.Client, error) {
-	return scp.NewClientBySSH(c.sshClient)
-}
-
+// GetSFTPClient returns the SFTP client shared by all operations, it is closed with the client.
 func (c *RemoteClient) GetSFTPClient() (*sftp.Client, error) {
-	return sftp.NewClient(c.sshClient)
+	c.sftpMux.Lock()
+	defer c.sftpMux.Unlock()
+
+	if c.sftpClient == nil {
+		sftpClient, err := sftp.NewClient(c.sshClient)
+		if err != nil {
+			return nil, err
+		}
+		c.sftpClient = sftpClient
+	}
+	return c.sftpClient, nil
 }
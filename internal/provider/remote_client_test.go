@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestWriteFileSFTPFromPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires POSIX permissions")
+	}
+
+	server := newTestSSHServer(t)
+	client, err := remoteClientFromResourceData(context.Background(), server.resourceData(t, nil), "")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer client.Close()
+
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.txt")
+	err = os.WriteFile(existing, []byte("old"), 0600)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	err = os.Chmod(existing, 0664)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	err = client.WriteFileSFTPFrom(strings.NewReader("new"), existing)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertFile(t, existing, "new", 0664)
+
+	created := filepath.Join(dir, "created.txt")
+	err = client.WriteFileSFTPFrom(strings.NewReader("new"), created)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertFile(t, created, "new", 0600)
+}
+
+func assertFile(t *testing.T, path string, content string, mode os.FileMode) {
+	actual, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if string(actual) != content {
+		t.Errorf("expected %s to contain %q, got %q", path, content, actual)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if info.Mode().Perm() != mode {
+		t.Errorf("expected %s to have permissions %o, got %o", path, mode, info.Mode().Perm())
+	}
+}
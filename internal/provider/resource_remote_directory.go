@@ -0,0 +1,256 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	deleteOnDestroyNever     = "never"
+	deleteOnDestroyEmpty     = "empty"
+	deleteOnDestroyRecursive = "recursive"
+)
+
+func resourceRemoteDirectory() *schema.Resource {
+	return &schema.Resource{
+		Description: "Directory on remote host.",
+
+		CreateContext: resourceRemoteDirectoryCreate,
+		ReadContext:   resourceRemoteDirectoryRead,
+		UpdateContext: resourceRemoteDirectoryUpdate,
+		DeleteContext: resourceRemoteDirectoryDelete,
+
+		Schema: map[string]*schema.Schema{
+			"conn": {
+				Type:        schema.TypeList,
+				MinItems:    0,
+				MaxItems:    1,
+				Optional:    true,
+				Description: "Connection to host where the directory is located.",
+				Elem:        connectionSchemaResource,
+			},
+			"path": {
+				Description: "Path to directory on remote host. Its parent has to exist, an existing directory is adopted and kept on destroy.",
+				Type:        schema.TypeString,
+				ForceNew:    true,
+				Required:    true,
+			},
+			"permissions": {
+				Description:  "Permissions of directory (in octal form).",
+				Type:         schema.TypeString,
+				Default:      "0755",
+				Optional:     true,
+				ValidateFunc: validation.StringMatch(permissionsRegexp, "expected an octal mode like 0755"),
+			},
+			"group": {
+				Description:  "Group ID (GID) of directory owner. Mutually exclusive with `group_name`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringMatch(ownerRegexp, "expected a group name or numeric ID"),
+			},
+			"group_name": {
+				Description:   "Group name of directory owner. Mutually exclusive with `group`.",
+				Type:          schema.TypeString,
+				Optional:      true,
+				ValidateFunc:  validation.StringMatch(ownerRegexp, "expected a group name or numeric ID"),
+				ConflictsWith: []string{"group"},
+			},
+			"owner": {
+				Description:  "User ID (UID) of directory owner. Mutually exclusive with `owner_name`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringMatch(ownerRegexp, "expected a user name or numeric ID"),
+			},
+			"owner_name": {
+				Description:   "User name of directory owner. Mutually exclusive with `owner`.",
+				Type:          schema.TypeString,
+				Optional:      true,
+				ValidateFunc:  validation.StringMatch(ownerRegexp, "expected a user name or numeric ID"),
+				ConflictsWith: []string{"owner"},
+			},
+			"recursive_ownership": {
+				Description: "Apply owner and group to everything in the directory too, without following symbolic links. Only the ownership of the directory itself is refreshed.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"delete_on_destroy": {
+				Description:  "What to delete on destroy, one of `never`, `empty` to delete the directory only if it is empty, or `recursive` to delete it with everything in it. Directories which existed before are never deleted.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      deleteOnDestroyEmpty,
+				ValidateFunc: validation.StringInSlice([]string{deleteOnDestroyNever, deleteOnDestroyEmpty, deleteOnDestroyRecursive}, false),
+			},
+			"created": {
+				Description: "Whether the directory was created by this resource rather than adopted.",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceRemoteDirectoryCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, err := meta.(*apiClient).getConnWithDefault(d)
+	if err != nil {
+		return diag.Errorf(err.Error())
+	}
+
+	setResourceID(d, conn)
+
+	become, err := becomeFromResourceData(conn)
+	if err != nil {
+		return diag.Errorf(err.Error())
+	}
+
+	client, err := meta.(*apiClient).getRemoteClient(ctx, conn)
+	if err != nil {
+		return diag.Errorf("unable to open remote client: %s", err.Error())
+	}
+	defer meta.(*apiClient).closeRemoteClient(conn)
+
+	path := d.Get("path").(string)
+	permissions := d.Get("permissions").(string)
+	group := d.Get("group").(string)
+	owner := d.Get("owner").(string)
+	if group == "" {
+		group = d.Get("group_name").(string)
+	}
+	if owner == "" {
+		owner = d.Get("owner_name").(string)
+	}
+
+	created, err := client.MakeDirectory(path, become)
+	if err != nil {
+		return diag.Errorf("unable to create remote directory: %s", err.Error())
+	}
+	// An update only recreates a directory deleted since it was created
+	if created || d.IsNewResource() {
+		d.Set("created", created)
+	}
+
+	if d.Get("recursive_ownership").(bool) {
+		err = client.ChownRecursive(path, owner, group, become)
+		if err != nil {
+			return diag.Errorf("unable to change ownership of remote directory: %s", err.Error())
+		}
+	} else {
+		if group != "" {
+			err = client.ChgrpFile(path, group, become)
+			if err != nil {
+				return diag.Errorf("unable to change group of remote directory: %s", err.Error())
+			}
+		}
+
+		if owner != "" {
+			err = client.ChownFile(path, owner, become)
+			if err != nil {
+				return diag.Errorf("unable to change owner of remote directory: %s", err.Error())
+			}
+		}
+	}
+
+	// Changing ownership may clear setuid and setgid bits
+	err = client.ChmodFile(path, permissions, become)
+	if err != nil {
+		return diag.Errorf("unable to change permissions of remote directory: %s", err.Error())
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceRemoteDirectoryRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, err := meta.(*apiClient).getConnWithDefault(d)
+	if err != nil {
+		return diag.Errorf(err.Error())
+	}
+
+	setResourceID(d, conn)
+
+	become, err := becomeFromResourceData(conn)
+	if err != nil {
+		return diag.Errorf(err.Error())
+	}
+
+	client, err := meta.(*apiClient).getRemoteClient(ctx, conn)
+	if err != nil {
+		return diag.Errorf("unable to open remote client: %s", err.Error())
+	}
+	defer meta.(*apiClient).closeRemoteClient(conn)
+
+	path := d.Get("path").(string)
+	group := d.Get("group").(string)
+	owner := d.Get("owner").(string)
+	group_name := d.Get("group_name").(string)
+	owner_name := d.Get("owner_name").(string)
+
+	dir, err := client.LoadDirectory(path, become)
+	if err != nil {
+		return diag.Errorf("unable to read remote directory: %s", err.Error())
+	}
+	if dir.Exists {
+		d.Set("permissions", dir.Permissions)
+
+		if owner != "" {
+			d.Set("owner", dir.Owner)
+		}
+		if owner_name != "" {
+			d.Set("owner_name", dir.OwnerName)
+		}
+
+		if group != "" {
+			d.Set("group", dir.Group)
+		}
+		if group_name != "" {
+			d.Set("group_name", dir.GroupName)
+		}
+	} else {
+		d.SetId("")
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceRemoteDirectoryUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return resourceRemoteDirectoryCreate(ctx, d, meta)
+}
+
+func resourceRemoteDirectoryDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Adopted directories are left alone, they may be used by others
+	deleteOnDestroy := d.Get("delete_on_destroy").(string)
+	if deleteOnDestroy == deleteOnDestroyNever || !d.Get("created").(bool) {
+		return diag.Diagnostics{}
+	}
+
+	conn, err := meta.(*apiClient).getConnWithDefault(d)
+	if err != nil {
+		return diag.Errorf(err.Error())
+	}
+
+	become, err := becomeFromResourceData(conn)
+	if err != nil {
+		return diag.Errorf(err.Error())
+	}
+
+	client, err := meta.(*apiClient).getRemoteClient(ctx, conn)
+	if err != nil {
+		return diag.Errorf("unable to open remote client: %s", err.Error())
+	}
+	defer meta.(*apiClient).closeRemoteClient(conn)
+
+	path := d.Get("path").(string)
+
+	if deleteOnDestroy == deleteOnDestroyRecursive {
+		err = client.RemoveDirectoryRecursive(path, become)
+	} else {
+		err = client.RemoveEmptyDirectories([]string{path}, become)
+	}
+	if err != nil {
+		return diag.Errorf("unable to delete remote directory: %s", err.Error())
+	}
+
+	return diag.Diagnostics{}
+}
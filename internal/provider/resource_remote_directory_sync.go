@@ -0,0 +1,283 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"path"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceRemoteDirectorySync() *schema.Resource {
+	return &schema.Resource{
+		Description: "Local directory mirrored into a directory on remote host. Files are transferred over a single SFTP session and only when their hash differs from the remote one.",
+
+		CreateContext: resourceRemoteDirectorySyncCreate,
+		ReadContext:   resourceRemoteDirectorySyncRead,
+		UpdateContext: resourceRemoteDirectorySyncUpdate,
+		DeleteContext: resourceRemoteDirectorySyncDelete,
+		CustomizeDiff: resourceRemoteDirectorySyncCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			"conn": {
+				Type:        schema.TypeList,
+				MinItems:    0,
+				MaxItems:    1,
+				Optional:    true,
+				Description: "Connection to host where the directory is located. `sudo` and `become` aren't supported.",
+				Elem:        connectionSchemaResource,
+			},
+			"source": {
+				Description: "Path to the local directory to mirror. Files keep their permissions, symbolic links to files are followed.",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"path": {
+				Description: "Path to directory on remote host. It is created when missing.",
+				Type:        schema.TypeString,
+				ForceNew:    true,
+				Required:    true,
+			},
+			"include": {
+				Description: "Glob patterns of files to sync, all files when empty. Patterns without `/` match names of files or of directories they are in, those with `/` match paths relative to `source`.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateGlob,
+				},
+			},
+			"exclude": {
+				Description: "Glob patterns of files not to sync, like `include`. Takes precedence over `include`.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateGlob,
+				},
+			},
+			"delete_extraneous": {
+				Description: "Also delete remote files which weren't synced by this resource and aren't in `source`. Files not selected by `include` and `exclude` are kept. Synced files are deleted either way once they are removed from `source` or no longer selected.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"files": {
+				Description: "SHA-256 hashes of the synced files by their path relative to `path`.",
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+// syncClient returns the client of the connection of d, which has to be released by
+// closeRemoteClient. Files are synced over SFTP, which can't gain privileges.
+func syncClient(ctx context.Context, d *schema.ResourceData, meta interface{}) (*schema.ResourceData, *RemoteClient, error) {
+	conn, err := meta.(*apiClient).getConnWithDefault(d)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	become, err := becomeFromResourceData(conn)
+	if err != nil {
+		return nil, nil, err
+	}
+	if become != nil {
+		return nil, nil, errors.New("remote_directory_sync doesn't support sudo and become")
+	}
+
+	client, err := meta.(*apiClient).getRemoteClient(ctx, conn)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn, client, nil
+}
+
+func resourceRemoteDirectorySyncCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, client, err := syncClient(ctx, d, meta)
+	if err != nil {
+		return diag.Errorf("unable to open remote client: %s", err.Error())
+	}
+	defer meta.(*apiClient).closeRemoteClient(conn)
+
+	setResourceID(d, conn)
+
+	dir := d.Get("path").(string)
+	include := resourceStringList(d, "include")
+	exclude := resourceStringList(d, "exclude")
+	local, err := localSyncTree(d.Get("source").(string), include, exclude)
+	if err != nil {
+		return diag.Errorf(err.Error())
+	}
+
+	_, err = client.MakeDirectory(dir, nil)
+	if err != nil {
+		return diag.Errorf("unable to create remote directory: %s", err.Error())
+	}
+
+	remote, err := client.HashTree(dir)
+	if err != nil {
+		return diag.Errorf("unable to hash remote files: %s", err.Error())
+	}
+
+	prior, _ := d.GetChange("files")
+	synced := prior.(map[string]interface{})
+
+	files := map[string]string{}
+	for rel, file := range local {
+		if remote[rel] != file.sha256 {
+			err = client.UploadFileSFTP(file.path, path.Join(dir, rel), file.mode)
+			if err != nil {
+				// Keep files synced before, so that they are still deleted later
+				for rel, hash := range synced {
+					_, ok := files[rel]
+					if !ok {
+						files[rel] = hash.(string)
+					}
+				}
+				d.Set("files", files)
+				return diag.Errorf("unable to upload file: %s", err.Error())
+			}
+		}
+		files[rel] = file.sha256
+	}
+
+	// Files synced before are deleted once they are no longer in source or no longer selected
+	obsolete := []string{}
+	for rel := range remote {
+		_, ok := local[rel]
+		if ok {
+			continue
+		}
+		_, wasSynced := synced[rel]
+		if wasSynced || (d.Get("delete_extraneous").(bool) && syncIncluded(include, exclude, rel)) {
+			obsolete = append(obsolete, rel)
+		}
+	}
+	sort.Strings(obsolete)
+
+	err = client.RemoveSyncedFilesSFTP(dir, obsolete)
+	if err != nil {
+		return diag.Errorf("unable to delete obsolete file: %s", err.Error())
+	}
+
+	d.Set("files", files)
+
+	return diag.Diagnostics{}
+}
+
+func resourceRemoteDirectorySyncRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, client, err := syncClient(ctx, d, meta)
+	if err != nil {
+		return diag.Errorf("unable to open remote client: %s", err.Error())
+	}
+	defer meta.(*apiClient).closeRemoteClient(conn)
+
+	setResourceID(d, conn)
+
+	dir := d.Get("path").(string)
+	remoteDir, err := client.LoadDirectory(dir, nil)
+	if err != nil {
+		return diag.Errorf("unable to read remote directory: %s", err.Error())
+	}
+	if remoteDir.Exists {
+		hashes, err := client.HashTree(dir)
+		if err != nil {
+			return diag.Errorf("unable to hash remote files: %s", err.Error())
+		}
+
+		// Other remote files only matter when they are to be deleted
+		synced := d.Get("files").(map[string]interface{})
+		deleteExtraneous := d.Get("delete_extraneous").(bool)
+		include := resourceStringList(d, "include")
+		exclude := resourceStringList(d, "exclude")
+
+		files := map[string]string{}
+		for rel, hash := range hashes {
+			_, ok := synced[rel]
+			if ok || (deleteExtraneous && syncIncluded(include, exclude, rel)) {
+				files[rel] = hash
+			}
+		}
+		d.Set("files", files)
+	} else {
+		d.SetId("")
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceRemoteDirectorySyncUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return resourceRemoteDirectorySyncCreate(ctx, d, meta)
+}
+
+func resourceRemoteDirectorySyncDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, client, err := syncClient(ctx, d, meta)
+	if err != nil {
+		return diag.Errorf("unable to open remote client: %s", err.Error())
+	}
+	defer meta.(*apiClient).closeRemoteClient(conn)
+
+	dir := d.Get("path").(string)
+
+	synced := []string{}
+	for rel := range d.Get("files").(map[string]interface{}) {
+		synced = append(synced, rel)
+	}
+	sort.Strings(synced)
+
+	err = client.RemoveSyncedFilesSFTP(dir, synced)
+	if err != nil {
+		return diag.Errorf("unable to delete synced files: %s", err.Error())
+	}
+
+	err = client.RemoveEmptyDirectoriesSFTP([]string{dir})
+	if err != nil {
+		return diag.Errorf("unable to delete remote directory: %s", err.Error())
+	}
+
+	return diag.Diagnostics{}
+}
+
+// resourceRemoteDirectorySyncCustomizeDiff plans an update when the hashes of the local files
+// differ from those in state, which are the hashes of the remote files after a refresh.
+func resourceRemoteDirectorySyncCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("source") || !d.NewValueKnown("include") || !d.NewValueKnown("exclude") {
+		return d.SetNewComputed("files")
+	}
+
+	include := []string{}
+	for _, pattern := range d.Get("include").([]interface{}) {
+		include = append(include, pattern.(string))
+	}
+	exclude := []string{}
+	for _, pattern := range d.Get("exclude").([]interface{}) {
+		exclude = append(exclude, pattern.(string))
+	}
+
+	local, err := localSyncTree(d.Get("source").(string), include, exclude)
+	if err != nil {
+		return err
+	}
+
+	files := map[string]interface{}{}
+	for rel, file := range local {
+		files[rel] = file.sha256
+	}
+
+	state := d.Get("files").(map[string]interface{})
+	changed := len(state) != len(files)
+	for rel, hash := range files {
+		if state[rel] != hash {
+			changed = true
+		}
+	}
+	if changed {
+		return d.SetNew("files", files)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccResourceRemoteDirectorySync(t *testing.T) {
+	source := t.TempDir()
+	writeSource := func(name string, content string) {
+		err := os.MkdirAll(filepath.Dir(filepath.Join(source, name)), 0755)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		err = os.WriteFile(filepath.Join(source, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	writeSource("index.html", "resource_20")
+	writeSource("assets/style.css", "resource_20")
+	writeSource("drafts/page.html", "resource_20")
+
+	config := fmt.Sprintf(`
+	resource "remote_directory_sync" "resource_20" {
+		conn {
+			host = "remotehost"
+			user = "root"
+			password = "password"
+		}
+		source = %q
+		path = "/tmp/resource_20"
+		exclude = ["drafts"]
+		delete_extraneous = true
+	}
+
+	data "remote_file" "data_17" {
+		conn {
+			host = "remotehost"
+			user = "root"
+			password = "password"
+		}
+		path = "/tmp/resource_20/assets/style.css"
+		depends_on = [remote_directory_sync.resource_20]
+	}
+	`, source)
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"remote_directory_sync.resource_20", "files.%", "2"),
+					resource.TestCheckResourceAttrSet(
+						"remote_directory_sync.resource_20", "files.assets/style.css"),
+					resource.TestCheckResourceAttr(
+						"data.remote_file.data_17", "content", "resource_20"),
+				),
+			},
+			{
+				PreConfig: func() { writeSource("assets/style.css", "resource_20 changed") },
+				Config:    config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.remote_file.data_17", "content", "resource_20 changed"),
+				),
+			},
+			{
+				PreConfig: func() {
+					writeFileToHost("remotehost:22", "/tmp/resource_20/extraneous.html", "extraneous", "root", "root")
+				},
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"remote_directory_sync.resource_20", "files.%", "2"),
+				),
+			},
+			{
+				PreConfig: func() {
+					writeFileToHost("remotehost:22", "/tmp/resource_20/index.html", "drifted", "root", "root")
+				},
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccResourceRemoteDirectorySyncOverExistingDirectory(t *testing.T) {
+	source := t.TempDir()
+	for _, name := range []string{"same.txt", "changed.txt", "notes.md"} {
+		err := os.WriteFile(filepath.Join(source, name), []byte("resource_24"), 0644)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+
+	config := func(exclude string) string {
+		return fmt.Sprintf(`
+		resource "remote_directory_sync" "resource_24" {
+			conn {
+				host = "remotehost"
+				user = "root"
+				password = "password"
+			}
+			source = %q
+			path = "/tmp/resource_24"
+			exclude = [%s]
+			delete_extraneous = true
+		}
+		`, source, exclude)
+	}
+
+	checkOnRemotehost := func(cmd string) resource.TestCheckFunc {
+		return func(s *terraform.State) error {
+			return checkOnHost("remotehost:22", cmd)
+		}
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			runOnHost("remotehost:22", "rm -rf /tmp/resource_24 && mkdir -p /tmp/resource_24/old && "+
+				"printf resource_24 > /tmp/resource_24/same.txt && touch -t 200001010000 /tmp/resource_24/same.txt && "+
+				"printf stale > /tmp/resource_24/changed.txt && printf old > /tmp/resource_24/old/extraneous.txt")
+		},
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config(""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"remote_directory_sync.resource_24", "files.%", "3"),
+					// Identical files aren't uploaded again
+					checkOnRemotehost("test $(stat -c %Y /tmp/resource_24/same.txt) -lt 978307200"),
+					checkOnRemotehost("grep -qx resource_24 /tmp/resource_24/changed.txt"),
+					checkOnRemotehost("test ! -e /tmp/resource_24/old"),
+				),
+			},
+			{
+				Config: config(`"*.md"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"remote_directory_sync.resource_24", "files.%", "2"),
+					checkOnRemotehost("test ! -e /tmp/resource_24/notes.md"),
+				),
+			},
+		},
+	})
+}
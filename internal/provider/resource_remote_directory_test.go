@@ -0,0 +1,158 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccResourceRemoteDirectory(t *testing.T) {
+	config := `
+	resource "remote_directory" "resource_18" {
+		conn {
+			host = "remotehost"
+			user = "root"
+			password = "password"
+		}
+		path = "/tmp/resource_18"
+		permissions = "0750"
+		owner_name = "bob"
+		group_name = "bob"
+		recursive_ownership = true
+		delete_on_destroy = "recursive"
+	}
+
+	data "remote_file" "data_16" {
+		conn {
+			host = "remotehost"
+			user = "root"
+			password = "password"
+		}
+		path = "/tmp/resource_18/file.txt"
+		depends_on = [remote_directory.resource_18]
+	}
+	`
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() {
+					runOnHost("remotehost:22", "mkdir -p /tmp/resource_18 && echo data_16 > /tmp/resource_18/file.txt")
+				},
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"remote_directory.resource_18", "permissions", "0750"),
+					resource.TestCheckResourceAttr(
+						"remote_directory.resource_18", "owner_name", "bob"),
+					resource.TestCheckResourceAttr(
+						"data.remote_file.data_16", "owner_name", "bob"),
+					resource.TestCheckResourceAttr(
+						"data.remote_file.data_16", "group_name", "bob"),
+				),
+			},
+			{
+				PreConfig: func() {
+					runOnHost("remotehost:22", "chmod 0700 /tmp/resource_18")
+				},
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				PreConfig: func() {
+					runOnHost("remotehost:22", "chmod 0750 /tmp/resource_18 && chown root /tmp/resource_18")
+				},
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccResourceRemoteDirectoryWithBecome(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+				resource "remote_directory" "resource_19" {
+					conn {
+						host = "remotehost"
+						user = "bob"
+						password = "pwd"
+						become {
+							password = "pwd"
+						}
+					}
+					path = "/etc/resource_19"
+					permissions = "2775"
+					group = "0"
+				}
+				`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"remote_directory.resource_19", "permissions", "2775"),
+					resource.TestCheckResourceAttr(
+						"remote_directory.resource_19", "group", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceRemoteDirectoryDeleteOnlyCreated(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			runOnHost("remotehost:22", "rm -rf /tmp/resource_26 /tmp/resource_27 && mkdir /tmp/resource_26")
+		},
+		ProviderFactories: providerFactories,
+		CheckDestroy: func(s *terraform.State) error {
+			err := checkOnHost("remotehost:22", "test -d /tmp/resource_26")
+			if err != nil {
+				return fmt.Errorf("expected adopted /tmp/resource_26 to be kept: %s", err.Error())
+			}
+			err = checkOnHost("remotehost:22", "test ! -e /tmp/resource_27")
+			if err != nil {
+				return fmt.Errorf("expected created /tmp/resource_27 to be deleted: %s", err.Error())
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: `
+				resource "remote_directory" "resource_26" {
+					conn {
+						host = "remotehost"
+						user = "root"
+						password = "password"
+					}
+					path = "/tmp/resource_26"
+				}
+
+				resource "remote_directory" "resource_27" {
+					conn {
+						host = "remotehost"
+						user = "root"
+						password = "password"
+					}
+					path = "/tmp/resource_27"
+				}
+				`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"remote_directory.resource_26", "created", "false"),
+					resource.TestCheckResourceAttr(
+						"remote_directory.resource_27", "created", "true"),
+				),
+			},
+		},
+	})
+}
@@ -2,9 +2,16 @@ package provider
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func resourceRemoteFile() *schema.Resource {
@@ -15,6 +22,10 @@ func resourceRemoteFile() *schema.Resource {
 		ReadContext:   resourceRemoteFileRead,
 		UpdateContext: resourceRemoteFileUpdate,
 		DeleteContext: resourceRemoteFileDelete,
+		CustomizeDiff: resourceRemoteFileCustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceRemoteFileImport,
+		},
 
 		Schema: map[string]*schema.Schema{
 			"conn": {
@@ -32,38 +43,123 @@ func resourceRemoteFile() *schema.Resource {
 				Required:    true,
 			},
 			"content": {
-				Description: "Content of file.",
-				Type:        schema.TypeString,
-				Required:    true,
+				Description:      "Content of file. Mutually exclusive with `content_base64` and `source`.",
+				Type:             schema.TypeString,
+				Optional:         true,
+				ExactlyOneOf:     []string{"content", "content_base64", "source"},
+				DiffSuppressFunc: suppressHashOnlyContent,
 			},
-			"permissions": {
-				Description: "Permissions of file (in octal form).",
+			"content_base64": {
+				Description:      "Base64 encoded content of file, for binary files. Mutually exclusive with `content` and `source`.",
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateFunc:     validation.StringIsBase64,
+				ExactlyOneOf:     []string{"content", "content_base64", "source"},
+				DiffSuppressFunc: suppressHashOnlyContent,
+			},
+			"source": {
+				Description:  "Path to a local file to upload, for large files. The file is streamed to the remote host and only its hash is kept in state. Mutually exclusive with `content` and `content_base64`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"content", "content_base64", "source"},
+			},
+			"source_sha256": {
+				Description: "SHA-256 hash of the remote file when `source` is set. Changes of the local or the remote file are detected by comparing their hashes.",
 				Type:        schema.TypeString,
-				Default:     "0644",
+				Computed:    true,
+			},
+			"hash_only": {
+				Description: "Keep only `content_sha256` and `size` in state instead of the content of file, for large or sensitive files. Changes of the configured or the remote content are detected by comparing hashes, the remote file is never downloaded.",
+				Type:        schema.TypeBool,
 				Optional:    true,
+				Default:     false,
 			},
-			"group": {
-				Description: "Group ID (GID) of file owner. Mutually exclusive with `group_name`.",
+			"content_sha256": {
+				Description: "SHA-256 hash of the content of file.",
 				Type:        schema.TypeString,
-				Optional:    true,
+				Computed:    true,
+			},
+			"size": {
+				Description: "Size of file in bytes.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			"permissions": {
+				Description:  "Permissions of file (in octal form).",
+				Type:         schema.TypeString,
+				Default:      "0644",
+				Optional:     true,
+				ValidateFunc: validation.StringMatch(permissionsRegexp, "expected an octal mode like 0644"),
+			},
+			"group": {
+				Description:  "Group ID (GID) of file owner. Mutually exclusive with `group_name`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringMatch(ownerRegexp, "expected a group name or numeric ID"),
 			},
 			"group_name": {
 				Description:   "Group name of file owner. Mutually exclusive with `group`.",
 				Type:          schema.TypeString,
 				Optional:      true,
+				ValidateFunc:  validation.StringMatch(ownerRegexp, "expected a group name or numeric ID"),
 				ConflictsWith: []string{"group"},
 			},
 			"owner": {
-				Description: "User ID (UID) of file owner. Mutually exclusive with `owner_name`.",
-				Type:        schema.TypeString,
-				Optional:    true,
+				Description:  "User ID (UID) of file owner. Mutually exclusive with `owner_name`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringMatch(ownerRegexp, "expected a user name or numeric ID"),
 			},
 			"owner_name": {
 				Description:   "User name of file owner. Mutually exclusive with `owner`.",
 				Type:          schema.TypeString,
 				Optional:      true,
+				ValidateFunc:  validation.StringMatch(ownerRegexp, "expected a user name or numeric ID"),
 				ConflictsWith: []string{"owner"},
 			},
+			"atomic": {
+				Description: "Replace the file atomically by writing a temporary file in the same directory, applying permissions and ownership to it and renaming it over `path`. Readers never see partially written content and the file is never accessible with wrong permissions.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"create_parents": {
+				Description: "Create missing parent directories of `path`.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"parent_permissions": {
+				Description:  "Permissions of parent directories created by `create_parents` (in octal form).",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "0755",
+				ValidateFunc: validation.StringMatch(permissionsRegexp, "expected an octal mode like 0755"),
+			},
+			"parent_owner": {
+				Description:  "User name or ID (UID) of owner of parent directories created by `create_parents`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringMatch(ownerRegexp, "expected a user name or numeric ID"),
+			},
+			"parent_group": {
+				Description:  "Group name or ID (GID) of parent directories created by `create_parents`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringMatch(ownerRegexp, "expected a group name or numeric ID"),
+			},
+			"delete_created_parents": {
+				Description: "Delete the parent directories listed in `created_parents` on destroy, as long as they are empty.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"created_parents": {
+				Description: "Parent directories created by this resource, outermost first.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
 		},
 	}
 }
@@ -76,55 +172,120 @@ func resourceRemoteFileCreate(ctx context.Context, d *schema.ResourceData, meta
 
 	setResourceID(d, conn)
 
+	become, err := becomeFromResourceData(conn)
+	if err != nil {
+		return diag.Errorf(err.Error())
+	}
+
+	content, _, err := configuredContent(d.GetRawConfig())
+	if err != nil {
+		return diag.Errorf(err.Error())
+	}
+
+	var reader io.Reader = strings.NewReader(content)
+	source, fromSource := d.GetOk("source")
+	if fromSource {
+		file, err := os.Open(source.(string))
+		if err != nil {
+			return diag.Errorf("unable to open source: %s", err.Error())
+		}
+		defer file.Close()
+		reader = file
+	}
+	digest := newContentDigest()
+	reader = io.TeeReader(reader, digest)
+
 	client, err := meta.(*apiClient).getRemoteClient(ctx, conn)
 	if err != nil {
 		return diag.Errorf("unable to open remote client: %s", err.Error())
 	}
+	defer meta.(*apiClient).closeRemoteClient(conn)
 
-	conn_sudo, ok := conn.GetOk("conn.0.sudo")
-	sudo := ok && conn_sudo.(bool)
-	content := d.Get("content").(string)
 	path := d.Get("path").(string)
 	permissions := d.Get("permissions").(string)
-	group := d.Get("group").(string)
-	owner := d.Get("owner").(string)
-	if group == "" {
-		group = d.Get("group_name").(string)
+	owner, group := remoteFileOwnership(d)
+
+	if d.Get("create_parents").(bool) {
+		created, err := client.CreateParents(path,
+			d.Get("parent_permissions").(string),
+			d.Get("parent_owner").(string),
+			d.Get("parent_group").(string),
+			become)
+		d.Set("created_parents", append(resourceStringList(d, "created_parents"), created...))
+		if err != nil {
+			return diag.Errorf("unable to create parent directories: %s", err.Error())
+		}
 	}
+
+	if d.Get("atomic").(bool) {
+		err = client.WriteFileAtomic(reader, path, permissions, owner, group, become)
+		if err != nil {
+			return diag.Errorf("unable to create remote file: %s", err.Error())
+		}
+	} else {
+		err = writeRemoteFile(client, reader, path, permissions, owner, group, become)
+		if err != nil {
+			return diag.Errorf(err.Error())
+		}
+	}
+
+	if fromSource {
+		d.Set("source_sha256", digest.sha256())
+	}
+	d.Set("content_sha256", digest.sha256())
+	d.Set("size", int(digest.size))
+	if d.Get("hash_only").(bool) {
+		d.Set("content", "")
+		d.Set("content_base64", "")
+	}
+
+	return diag.Diagnostics{}
+}
+
+// remoteFileOwnership returns the configured owner and group of the file, by ID or by name.
+func remoteFileOwnership(d *schema.ResourceData) (owner string, group string) {
+	owner = d.Get("owner").(string)
+	group = d.Get("group").(string)
 	if owner == "" {
 		owner = d.Get("owner_name").(string)
 	}
-
-	err = client.WriteFile(content, path, permissions, sudo)
-	if err != nil {
-		return diag.Errorf("unable to create remote file: %s", err.Error())
+	if group == "" {
+		group = d.Get("group_name").(string)
 	}
+	return owner, group
+}
 
-	err = client.ChmodFile(path, permissions, sudo)
+func writeRemoteFile(client *RemoteClient, content io.Reader, path string, permissions string, owner string, group string, become *Become) error {
+	err := client.WriteFileFrom(content, path, become)
 	if err != nil {
-		return diag.Errorf("unable to change permissions of remote file: %s", err.Error())
+		return fmt.Errorf("unable to create remote file: %s", err.Error())
 	}
 
+	return setRemoteFileAttributes(client, path, permissions, owner, group, become)
+}
+
+func setRemoteFileAttributes(client *RemoteClient, path string, permissions string, owner string, group string, become *Become) error {
 	if group != "" {
-		err = client.ChgrpFile(path, group, sudo)
+		err := client.ChgrpFile(path, group, become)
 		if err != nil {
-			return diag.Errorf("unable to change group of remote file: %s", err.Error())
+			return fmt.Errorf("unable to change group of remote file: %s", err.Error())
 		}
 	}
 
 	if owner != "" {
-		err = client.ChownFile(path, owner, sudo)
+		err := client.ChownFile(path, owner, become)
 		if err != nil {
-			return diag.Errorf("unable to change owner of remote file: %s", err.Error())
+			return fmt.Errorf("unable to change owner of remote file: %s", err.Error())
 		}
 	}
 
-	err = meta.(*apiClient).closeRemoteClient(conn)
+	// Changing ownership may clear setuid and setgid bits
+	err := client.ChmodFile(path, permissions, become)
 	if err != nil {
-		return diag.Errorf("unable to close remote client: %s", err.Error())
+		return fmt.Errorf("unable to change permissions of remote file: %s", err.Error())
 	}
 
-	return diag.Diagnostics{}
+	return nil
 }
 
 func resourceRemoteFileRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -135,79 +296,106 @@ func resourceRemoteFileRead(ctx context.Context, d *schema.ResourceData, meta in
 
 	setResourceID(d, conn)
 
+	become, err := becomeFromResourceData(conn)
+	if err != nil {
+		return diag.Errorf(err.Error())
+	}
+
 	client, err := meta.(*apiClient).getRemoteClient(ctx, conn)
 	if err != nil {
 		return diag.Errorf("unable to open remote client: %s", err.Error())
 	}
+	defer meta.(*apiClient).closeRemoteClient(conn)
 
-	conn_sudo, ok := conn.GetOk("conn.0.sudo")
-	sudo := ok && conn_sudo.(bool)
 	path := d.Get("path").(string)
 	group := d.Get("group").(string)
 	owner := d.Get("owner").(string)
 	group_name := d.Get("group_name").(string)
 	owner_name := d.Get("owner_name").(string)
 
-	exists, err := client.FileExists(path, sudo)
+	_, fromSource := d.GetOk("source")
+	hashOnly := fromSource || d.Get("hash_only").(bool)
+
+	var file *RemoteFile
+	if hashOnly {
+		file, err = client.LoadFileAttributes(path, become)
+	} else {
+		file, err = client.LoadFile(path, become)
+	}
 	if err != nil {
-		return diag.Errorf("unable to check if remote file exists: %s", err.Error())
+		return diag.Errorf("unable to read remote file: %s", err.Error())
 	}
-	if exists {
-		content, err := client.ReadFile(path, sudo)
-		if err != nil {
-			return diag.Errorf("unable to read remote file: %s", err.Error())
-		}
-		d.Set("content", content)
-
-		permissions, err := client.ReadFilePermissions(path, sudo)
-		if err != nil {
-			return diag.Errorf("unable to read remote file permissions: %s", err.Error())
+	if file.Exists {
+		_, ok := d.GetOk("content_base64")
+		if hashOnly {
+			hash, err := client.SHA256File(path, become)
+			if err != nil {
+				return diag.Errorf("unable to hash remote file: %s", err.Error())
+			}
+			if fromSource {
+				d.Set("source_sha256", hash)
+			}
+			d.Set("content_sha256", hash)
+		} else {
+			if ok {
+				d.Set("content_base64", base64.StdEncoding.EncodeToString([]byte(file.Content)))
+			} else {
+				d.Set("content", file.Content)
+			}
+			d.Set("content_sha256", fmt.Sprintf("%x", sha256.Sum256([]byte(file.Content))))
 		}
-		d.Set("permissions", permissions)
+		d.Set("size", int(file.Size))
+		d.Set("permissions", file.Permissions)
 
 		if owner != "" {
-			owner, err := client.ReadFileOwner(path, sudo)
-			if err != nil {
-				return diag.Errorf("unable to read remote file owner: %s", err.Error())
-			}
-			d.Set("owner", owner)
+			d.Set("owner", file.Owner)
 		}
 		if owner_name != "" {
-			owner_name, err := client.ReadFileOwnerName(path, sudo)
-			if err != nil {
-				return diag.Errorf("unable to read remote file owner_name: %s", err.Error())
-			}
-			d.Set("owner_name", owner_name)
+			d.Set("owner_name", file.OwnerName)
 		}
 
 		if group != "" {
-			group, err := client.ReadFileGroup(path, sudo)
-			if err != nil {
-				return diag.Errorf("unable to read remote file group: %s", err.Error())
-			}
-			d.Set("group", group)
+			d.Set("group", file.Group)
 		}
 		if group_name != "" {
-			group_name, err := client.ReadFileGroupName(path, sudo)
-			if err != nil {
-				return diag.Errorf("unable to read remote file group_name: %s", err.Error())
-			}
-			d.Set("group_name", group_name)
+			d.Set("group_name", file.GroupName)
 		}
 	} else {
 		d.SetId("")
 	}
 
-	err = meta.(*apiClient).closeRemoteClient(conn)
-	if err != nil {
-		return diag.Errorf("unable to close remote client: %s", err.Error())
-	}
-
 	return diag.Diagnostics{}
 }
 
 func resourceRemoteFileUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	return resourceRemoteFileCreate(ctx, d, meta)
+	if d.HasChanges("content", "content_base64", "source", "source_sha256", "content_sha256", "size", "hash_only") {
+		return resourceRemoteFileCreate(ctx, d, meta)
+	}
+
+	// Only attributes of the file changed, so the content isn't written again
+	conn, err := meta.(*apiClient).getConnWithDefault(d)
+	if err != nil {
+		return diag.Errorf(err.Error())
+	}
+
+	become, err := becomeFromResourceData(conn)
+	if err != nil {
+		return diag.Errorf(err.Error())
+	}
+
+	client, err := meta.(*apiClient).getRemoteClient(ctx, conn)
+	if err != nil {
+		return diag.Errorf("unable to open remote client: %s", err.Error())
+	}
+	defer meta.(*apiClient).closeRemoteClient(conn)
+
+	owner, group := remoteFileOwnership(d)
+	err = setRemoteFileAttributes(client, d.Get("path").(string), d.Get("permissions").(string), owner, group, become)
+	if err != nil {
+		return diag.Errorf(err.Error())
+	}
+
+	return diag.Diagnostics{}
 }
 
 func resourceRemoteFileDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -216,29 +404,35 @@ func resourceRemoteFileDelete(ctx context.Context, d *schema.ResourceData, meta
 		return diag.Errorf(err.Error())
 	}
 
+	become, err := becomeFromResourceData(conn)
+	if err != nil {
+		return diag.Errorf(err.Error())
+	}
+
 	client, err := meta.(*apiClient).getRemoteClient(ctx, conn)
 	if err != nil {
 		return diag.Errorf("unable to open remote client: %s", err.Error())
 	}
+	defer meta.(*apiClient).closeRemoteClient(conn)
 
-	conn_sudo, ok := conn.GetOk("conn.0.sudo")
-	sudo := ok && conn_sudo.(bool)
 	path := d.Get("path").(string)
 
-	exists, err := client.FileExists(path, sudo)
+	exists, err := client.FileExists(path, become)
 	if err != nil {
 		return diag.Errorf("unable to check if remote file exists: %s", err.Error())
 	}
 	if exists {
-		err = client.DeleteFile(path, sudo)
+		err = client.DeleteFile(path, become)
 		if err != nil {
 			return diag.Errorf("unable to delete remote file: %s", err.Error())
 		}
 	}
 
-	err = meta.(*apiClient).closeRemoteClient(conn)
-	if err != nil {
-		return diag.Errorf("unable to close remote client: %s", err.Error())
+	if d.Get("delete_created_parents").(bool) {
+		err = client.RemoveEmptyDirectories(resourceStringList(d, "created_parents"), become)
+		if err != nil {
+			return diag.Errorf("unable to delete created parent directories: %s", err.Error())
+		}
 	}
 
 	return diag.Diagnostics{}
@@ -1,11 +1,14 @@
 package provider
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 func TestAccResourceRemoteFile(t *testing.T) {
@@ -217,3 +220,580 @@ func TestAccResourceRemoteFileThroughProxy(t *testing.T) {
 		},
 	})
 }
+
+func TestAccResourceRemoteFileThroughMultipleProxies(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+				resource "remote_file" "resource_9" {
+					provider = remotehost2-through-remotehost2-and-remotehost
+
+					path = "/tmp/resource_9.txt"
+					content = "resource_9"
+				}
+				`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						"remote_file.resource_9", "id", regexp.MustCompile(`remotehost2:22\|remotehost:22\|remotehost2:1022:/tmp/resource_9.txt`)),
+					resource.TestMatchResourceAttr(
+						"remote_file.resource_9", "content", regexp.MustCompile("resource_9")),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceRemoteFileWithBecome(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+				resource "remote_file" "resource_10" {
+					conn {
+						host = "remotehost"
+						user = "bob"
+						password = "pwd"
+						become {
+							method = "sudo"
+							password = "pwd"
+						}
+					}
+					path = "/root/resource_10.txt"
+					content = "resource_10"
+					permissions = "0600"
+				}
+				`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						"remote_file.resource_10", "content", regexp.MustCompile("resource_10")),
+					resource.TestMatchResourceAttr(
+						"remote_file.resource_10", "permissions", regexp.MustCompile("0600")),
+				),
+			},
+			{
+				Config: `
+				resource "remote_file" "resource_10" {
+					conn {
+						host = "remotehost"
+						user = "bob"
+						password = "pwd"
+						become {
+							method = "su"
+							password = "password"
+						}
+					}
+					path = "/root/resource_10.txt"
+					content = "resource_10 through su"
+					permissions = "0600"
+				}
+				`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						"remote_file.resource_10", "content", regexp.MustCompile("resource_10 through su")),
+				),
+			},
+			{
+				Config: `
+				resource "remote_file" "resource_10" {
+					conn {
+						host = "remotehost"
+						user = "bob"
+						password = "pwd"
+						become {
+							password = "wrong"
+						}
+					}
+					path = "/root/resource_10.txt"
+					content = "resource_10"
+				}
+				`,
+				ExpectError: regexp.MustCompile("incorrect become password"),
+			},
+		},
+	})
+}
+
+func TestAccResourceRemoteFileAtomic(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+				resource "remote_file" "resource_11" {
+					conn {
+						host = "remotehost"
+						user = "root"
+						password = "password"
+					}
+					path = "/tmp/resource_11.txt"
+					content = "resource_11"
+					permissions = "0640"
+					owner_name = "bob"
+					group_name = "bob"
+					atomic = true
+				}
+				`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						"remote_file.resource_11", "content", regexp.MustCompile("resource_11")),
+					resource.TestMatchResourceAttr(
+						"remote_file.resource_11", "permissions", regexp.MustCompile("0640")),
+					resource.TestMatchResourceAttr(
+						"remote_file.resource_11", "owner_name", regexp.MustCompile("bob")),
+				),
+			},
+			{
+				Config: `
+				resource "remote_file" "resource_11" {
+					conn {
+						host = "remotehost"
+						user = "root"
+						password = "password"
+						sudo = true
+					}
+					path = "/tmp/resource_11.txt"
+					content = "resource_11 replaced"
+					permissions = "0600"
+					owner_name = "root"
+					group_name = "root"
+					atomic = true
+				}
+				`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						"remote_file.resource_11", "content", regexp.MustCompile("resource_11 replaced")),
+					resource.TestMatchResourceAttr(
+						"remote_file.resource_11", "permissions", regexp.MustCompile("0600")),
+				),
+			},
+			{
+				Config: `
+				resource "remote_file" "resource_11" {
+					conn {
+						host = "remotehost"
+						user = "root"
+						password = "password"
+					}
+					path = "/tmp/resource_11.txt"
+					content = "resource_11 not written"
+					owner_name = "nobody-with-this-name"
+					atomic = true
+				}
+				`,
+				ExpectError: regexp.MustCompile("no user named nobody-with-this-name"),
+			},
+		},
+	})
+}
+
+func TestAccResourceRemoteFileWithBinaryContent(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+				resource "remote_file" "resource_12" {
+					conn {
+						host = "remotehost"
+						user = "root"
+						password = "password"
+					}
+					path = "/tmp/resource_12.bin"
+					content_base64 = "AAEC/w0KBA=="
+				}
+				`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"remote_file.resource_12", "content_base64", "AAEC/w0KBA=="),
+				),
+			},
+			{
+				Config: `
+				resource "remote_file" "resource_12" {
+					conn {
+						host = "remotehost"
+						user = "root"
+						password = "password"
+						sudo = true
+					}
+					path = "/tmp/resource_12.bin"
+					content_base64 = "BA0KAP8BAg=="
+				}
+				`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"remote_file.resource_12", "content_base64", "BA0KAP8BAg=="),
+				),
+			},
+			{
+				Config: `
+				resource "remote_file" "resource_12" {
+					conn {
+						host = "remotehost"
+						user = "bob"
+						password = "pwd"
+						become {
+							password = "pwd"
+						}
+					}
+					path = "/tmp/resource_12.bin"
+					content_base64 = "AAEC/w0KBA0K"
+				}
+
+				data "remote_file" "data_13" {
+					conn {
+						host = "remotehost"
+						user = "root"
+						password = "password"
+					}
+					path = remote_file.resource_12.path
+				}
+				`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"remote_file.resource_12", "content_base64", "AAEC/w0KBA0K"),
+					resource.TestCheckResourceAttr(
+						"data.remote_file.data_13", "content_base64", "AAEC/w0KBA0K"),
+				),
+			},
+			{
+				Config: `
+				resource "remote_file" "resource_12" {
+					conn {
+						host = "remotehost"
+						user = "root"
+						password = "password"
+					}
+					path = "/tmp/resource_12.bin"
+					content = "text"
+					content_base64 = "AAEC/w0KBA=="
+				}
+				`,
+				ExpectError: regexp.MustCompile("only one of `content,content_base64` can be specified"),
+			},
+		},
+	})
+}
+
+func TestAccResourceRemoteFileFromSource(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "resource_13.txt")
+	writeSource := func(content string) {
+		err := os.WriteFile(source, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	writeSource("resource_13")
+
+	config := fmt.Sprintf(`
+	resource "remote_file" "resource_13" {
+		conn {
+			host = "remotehost"
+			user = "root"
+			password = "password"
+		}
+		path = "/tmp/resource_13.txt"
+		source = %q
+	}
+
+	data "remote_file" "data_14" {
+		conn {
+			host = "remotehost"
+			user = "root"
+			password = "password"
+		}
+		path = remote_file.resource_13.path
+		depends_on = [remote_file.resource_13]
+	}
+	`, source)
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"remote_file.resource_13", "source_sha256", "458d57f1b3576125be2f8322017c416a3865a343e58aa497a70f219bb99f2bb5"),
+					resource.TestCheckResourceAttr(
+						"data.remote_file.data_14", "content", "resource_13"),
+				),
+			},
+			{
+				PreConfig: func() { writeSource("resource_13 changed") },
+				Config:    config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.remote_file.data_14", "content", "resource_13 changed"),
+				),
+			},
+			{
+				PreConfig: func() {
+					writeFileToHost("remotehost:22", "/tmp/resource_13.txt", "drifted", "root", "root")
+				},
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccResourceRemoteFileHashOnly(t *testing.T) {
+	config := `
+	resource "remote_file" "resource_14" {
+		conn {
+			host = "remotehost"
+			user = "root"
+			password = "password"
+		}
+		path = "/tmp/resource_14.txt"
+		content = "resource_14"
+		hash_only = true
+	}
+	`
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"remote_file.resource_14", "content", ""),
+					resource.TestCheckResourceAttr(
+						"remote_file.resource_14", "content_sha256", "8c0ea07bbe158a472e1f70423ceb1cc737758afa9029762f08eb59b41b2f2df6"),
+					resource.TestCheckResourceAttr(
+						"remote_file.resource_14", "size", "11"),
+				),
+			},
+			{
+				PreConfig: func() {
+					writeFileToHost("remotehost:22", "/tmp/resource_14.txt", "drifted", "root", "root")
+				},
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccResourceRemoteFileImport(t *testing.T) {
+	config := `
+	resource "remote_file" "resource_15" {
+		provider = remotehost
+
+		path = "/tmp/resource_15.txt"
+		content = "resource_15"
+		permissions = "0600"
+	}
+	`
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			{
+				Config:            config,
+				ResourceName:      "remote_file.resource_15",
+				ImportState:       true,
+				ImportStateId:     "root@remotehost:22:/tmp/resource_15.txt",
+				ImportStateVerify: true,
+			},
+			{
+				Config:        config,
+				ResourceName:  "remote_file.resource_15",
+				ImportState:   true,
+				ImportStateId: "remotehost2:22:/tmp/resource_15.txt",
+				ExpectError:   regexp.MustCompile("doesn't match the connection of the provider"),
+			},
+		},
+	})
+}
+
+func TestAccResourceRemoteFileCreateParents(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+				resource "remote_file" "resource_16" {
+					conn {
+						host = "remotehost"
+						user = "root"
+						password = "password"
+					}
+					path = "/tmp/resource_16/conf.d/app.conf"
+					content = "resource_16"
+					create_parents = true
+					parent_permissions = "0750"
+					parent_owner = "bob"
+					delete_created_parents = true
+				}
+
+				resource "remote_file" "resource_17" {
+					conn {
+						host = "remotehost"
+						user = "bob"
+						password = "pwd"
+						become {
+							password = "pwd"
+						}
+					}
+					path = "/etc/resource_17/conf.d/app.conf"
+					content = "resource_17"
+					create_parents = true
+					parent_group = "bob"
+					delete_created_parents = true
+				}
+
+				data "remote_file" "data_15" {
+					conn {
+						host = "remotehost"
+						user = "bob"
+						password = "pwd"
+					}
+					path = remote_file.resource_16.path
+				}
+				`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"remote_file.resource_16", "created_parents.#", "2"),
+					resource.TestCheckResourceAttr(
+						"remote_file.resource_16", "created_parents.0", "/tmp/resource_16"),
+					resource.TestCheckResourceAttr(
+						"remote_file.resource_16", "created_parents.1", "/tmp/resource_16/conf.d"),
+					resource.TestCheckResourceAttr(
+						"data.remote_file.data_15", "content", "resource_16"),
+					resource.TestCheckResourceAttr(
+						"remote_file.resource_17", "created_parents.#", "2"),
+					resource.TestCheckResourceAttr(
+						"remote_file.resource_17", "created_parents.0", "/etc/resource_17"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceRemoteFileWithSSHConfigAndExplicitPort(t *testing.T) {
+	key, err := filepath.Abs("../../tests/key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Port 1022 of remotehost is only reachable from remotehost2, so refreshing and destroying
+	// fail unless they also use the explicit port
+	sshConfigPath := filepath.Join(t.TempDir(), "config")
+	sshConfig := fmt.Sprintf(`
+Host target
+    HostName remotehost
+    Port 1022
+    User root
+    IdentityFile %s
+`, key)
+	err = os.WriteFile(sshConfigPath, []byte(sshConfig), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := fmt.Sprintf(`
+	resource "remote_file" "resource_23" {
+		conn {
+			host = "target"
+			port = 22
+			ssh_config = true
+			ssh_config_path = "%s"
+		}
+		path = "/tmp/resource_23.txt"
+		content = "resource_23"
+	}
+	`, sshConfigPath)
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		CheckDestroy: func(s *terraform.State) error {
+			err := checkOnHost("remotehost:22", "test ! -e /tmp/resource_23.txt")
+			if err != nil {
+				return fmt.Errorf("expected /tmp/resource_23.txt to be deleted: %s", err.Error())
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"remote_file.resource_23", "conn.0.port_explicit", "true"),
+					func(s *terraform.State) error {
+						return checkOnHost("remotehost:22", "grep -qx resource_23 /tmp/resource_23.txt")
+					},
+				),
+			},
+			{
+				Config:   config,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccResourceRemoteFileUpdateAttributesOnly(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "source.txt")
+	err := os.WriteFile(source, []byte("resource_25"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := func(permissions string) string {
+		return fmt.Sprintf(`
+		resource "remote_file" "resource_25" {
+			conn {
+				host = "remotehost"
+				user = "root"
+				password = "password"
+			}
+			path = "/tmp/resource_25.txt"
+			source = %q
+			permissions = "%s"
+		}
+		`, source, permissions)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config("0644"),
+			},
+			{
+				// The content is left alone, so the modification time stays in the past
+				PreConfig: func() { runOnHost("remotehost:22", "touch -t 200001010000 /tmp/resource_25.txt") },
+				Config:    config("0600"),
+				Check: resource.ComposeTestCheckFunc(
+					func(s *terraform.State) error {
+						return checkOnHost("remotehost:22", "test $(stat -c %a /tmp/resource_25.txt) = 600")
+					},
+					func(s *terraform.State) error {
+						return checkOnHost("remotehost:22", "test $(stat -c %Y /tmp/resource_25.txt) -lt 978307200")
+					},
+				),
+			},
+		},
+	})
+}
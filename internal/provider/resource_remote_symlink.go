@@ -0,0 +1,134 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceRemoteSymlink() *schema.Resource {
+	return &schema.Resource{
+		Description: "Symbolic link on remote host. The link is replaced atomically, so it always exists while its target changes.",
+
+		CreateContext: resourceRemoteSymlinkCreate,
+		ReadContext:   resourceRemoteSymlinkRead,
+		UpdateContext: resourceRemoteSymlinkUpdate,
+		DeleteContext: resourceRemoteSymlinkDelete,
+
+		Schema: map[string]*schema.Schema{
+			"conn": {
+				Type:        schema.TypeList,
+				MinItems:    0,
+				MaxItems:    1,
+				Optional:    true,
+				Description: "Connection to host where the link is located.",
+				Elem:        connectionSchemaResource,
+			},
+			"path": {
+				Description: "Path to link on remote host.",
+				Type:        schema.TypeString,
+				ForceNew:    true,
+				Required:    true,
+			},
+			"target": {
+				Description:  "Path the link points to, relative to the directory of the link unless absolute. It doesn't have to exist.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+		},
+	}
+}
+
+func resourceRemoteSymlinkCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, err := meta.(*apiClient).getConnWithDefault(d)
+	if err != nil {
+		return diag.Errorf(err.Error())
+	}
+
+	setResourceID(d, conn)
+
+	become, err := becomeFromResourceData(conn)
+	if err != nil {
+		return diag.Errorf(err.Error())
+	}
+
+	client, err := meta.(*apiClient).getRemoteClient(ctx, conn)
+	if err != nil {
+		return diag.Errorf("unable to open remote client: %s", err.Error())
+	}
+	defer meta.(*apiClient).closeRemoteClient(conn)
+
+	err = client.WriteSymlinkAtomic(d.Get("target").(string), d.Get("path").(string), become)
+	if err != nil {
+		return diag.Errorf("unable to create remote symlink: %s", err.Error())
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceRemoteSymlinkRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, err := meta.(*apiClient).getConnWithDefault(d)
+	if err != nil {
+		return diag.Errorf(err.Error())
+	}
+
+	setResourceID(d, conn)
+
+	become, err := becomeFromResourceData(conn)
+	if err != nil {
+		return diag.Errorf(err.Error())
+	}
+
+	client, err := meta.(*apiClient).getRemoteClient(ctx, conn)
+	if err != nil {
+		return diag.Errorf("unable to open remote client: %s", err.Error())
+	}
+	defer meta.(*apiClient).closeRemoteClient(conn)
+
+	link, err := client.ReadSymlink(d.Get("path").(string), become)
+	if err != nil {
+		return diag.Errorf("unable to read remote symlink: %s", err.Error())
+	}
+	if link.IsSymlink {
+		d.Set("target", link.Target)
+	} else if link.Exists {
+		// Something else replaced the link, which an update replaces in turn
+		d.Set("target", "")
+	} else {
+		d.SetId("")
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceRemoteSymlinkUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return resourceRemoteSymlinkCreate(ctx, d, meta)
+}
+
+func resourceRemoteSymlinkDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, err := meta.(*apiClient).getConnWithDefault(d)
+	if err != nil {
+		return diag.Errorf(err.Error())
+	}
+
+	become, err := becomeFromResourceData(conn)
+	if err != nil {
+		return diag.Errorf(err.Error())
+	}
+
+	client, err := meta.(*apiClient).getRemoteClient(ctx, conn)
+	if err != nil {
+		return diag.Errorf("unable to open remote client: %s", err.Error())
+	}
+	defer meta.(*apiClient).closeRemoteClient(conn)
+
+	err = client.DeleteSymlink(d.Get("path").(string), become)
+	if err != nil {
+		return diag.Errorf("unable to delete remote symlink: %s", err.Error())
+	}
+
+	return diag.Diagnostics{}
+}
@@ -0,0 +1,133 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceRemoteSymlink(t *testing.T) {
+	config := func(target string) string {
+		return `
+		resource "remote_symlink" "resource_21" {
+			conn {
+				host = "remotehost"
+				user = "root"
+				password = "password"
+			}
+			path = "/tmp/resource_21"
+			target = "` + target + `"
+		}
+
+		data "remote_file" "data_18" {
+			conn {
+				host = "remotehost"
+				user = "root"
+				password = "password"
+			}
+			path = "/tmp/resource_21/version.txt"
+			depends_on = [remote_symlink.resource_21]
+		}
+		`
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() {
+					runOnHost("remotehost:22", "mkdir -p /tmp/releases_21/v1 /tmp/releases_21/v2 && printf v1 > /tmp/releases_21/v1/version.txt && printf v2 > /tmp/releases_21/v2/version.txt")
+				},
+				Config: config("releases_21/v1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"remote_symlink.resource_21", "target", "releases_21/v1"),
+					resource.TestCheckResourceAttr(
+						"data.remote_file.data_18", "content", "v1"),
+				),
+			},
+			{
+				Config: config("releases_21/v2"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"remote_symlink.resource_21", "target", "releases_21/v2"),
+					resource.TestCheckResourceAttr(
+						"data.remote_file.data_18", "content", "v2"),
+				),
+			},
+			{
+				PreConfig: func() {
+					runOnHost("remotehost:22", "ln -sfn releases_21/v1 /tmp/resource_21")
+				},
+				Config:             config("releases_21/v2"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				PreConfig: func() {
+					runOnHost("remotehost:22", "rm /tmp/resource_21 && echo regular > /tmp/resource_21")
+				},
+				Config:             config("releases_21/v2"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: config("releases_21/v2"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"remote_symlink.resource_21", "target", "releases_21/v2"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceRemoteSymlinkWithBecome(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+				resource "remote_symlink" "resource_22" {
+					conn {
+						host = "remotehost"
+						user = "bob"
+						password = "pwd"
+						become {
+							password = "pwd"
+						}
+					}
+					path = "/etc/resource_22"
+					target = "/tmp"
+				}
+				`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"remote_symlink.resource_22", "target", "/tmp"),
+				),
+			},
+			{
+				Config: `
+				resource "remote_symlink" "resource_22" {
+					conn {
+						host = "remotehost"
+						user = "bob"
+						password = "pwd"
+						become {
+							password = "pwd"
+						}
+					}
+					path = "/etc/resource_22"
+					target = "/var"
+				}
+				`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"remote_symlink.resource_22", "target", "/var"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,171 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	retryOnConnectionRefused = "connection_refused"
+	retryOnConnectionReset   = "connection_reset"
+	retryOnTimeout           = "timeout"
+	retryOnUnreachable       = "unreachable"
+	retryOnAuthFailure       = "auth_failure"
+)
+
+var retryOnErrors = []string{
+	retryOnConnectionRefused,
+	retryOnConnectionReset,
+	retryOnTimeout,
+	retryOnUnreachable,
+	retryOnAuthFailure,
+}
+
+// retryErrorMessages maps kinds of errors to parts of the messages of errors of that kind.
+var retryErrorMessages = map[string][]string{
+	retryOnConnectionRefused: {"connection refused"},
+	retryOnConnectionReset:   {"connection reset by peer", "broken pipe", "handshake failed: EOF"},
+	retryOnTimeout:           {"i/o timeout", "timed out", "deadline exceeded"},
+	retryOnUnreachable:       {"no route to host", "network is unreachable", "host is down", "no such host"},
+	retryOnAuthFailure:       {"unable to authenticate"},
+}
+
+var retrySchemaResource = &schema.Resource{
+	Schema: map[string]*schema.Schema{
+		"timeout": {
+			Type:         schema.TypeInt,
+			Required:     true,
+			ValidateFunc: validation.IntAtLeast(0),
+			Description:  "The maximum amount of time, in milliseconds, to keep retrying to establish the connection.",
+		},
+		"initial_backoff": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      1000,
+			ValidateFunc: validation.IntAtLeast(1),
+			Description:  "The time, in milliseconds, to wait before the first retry. The wait is doubled after each attempt.",
+		},
+		"max_backoff": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      30000,
+			ValidateFunc: validation.IntAtLeast(1),
+			Description:  "The maximum time, in milliseconds, to wait between attempts.",
+		},
+		"retry_on": {
+			Type:     schema.TypeSet,
+			Optional: true,
+			Elem: &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringInSlice(retryOnErrors, false),
+			},
+			Description: "Kinds of errors to retry on, any of `connection_refused`, `connection_reset`, `timeout`, `unreachable` and `auth_failure`. Defaults to all except `auth_failure`.",
+		},
+	},
+}
+
+type retrySettings struct {
+	timeout        time.Duration
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	retryOn        []string
+}
+
+func retrySettingsFromResourceData(prefixKey func(string) string, d *schema.ResourceData) *retrySettings {
+	_, ok := d.GetOk(prefixKey("retry"))
+	if !ok {
+		return nil
+	}
+
+	settings := &retrySettings{
+		timeout:        time.Duration(d.Get(prefixKey("retry.0.timeout")).(int)) * time.Millisecond,
+		initialBackoff: time.Duration(d.Get(prefixKey("retry.0.initial_backoff")).(int)) * time.Millisecond,
+		maxBackoff:     time.Duration(d.Get(prefixKey("retry.0.max_backoff")).(int)) * time.Millisecond,
+	}
+
+	retryOn, ok := d.GetOk(prefixKey("retry.0.retry_on"))
+	if ok {
+		for _, kind := range retryOn.(*schema.Set).List() {
+			settings.retryOn = append(settings.retryOn, kind.(string))
+		}
+	} else {
+		settings.retryOn = []string{retryOnConnectionRefused, retryOnConnectionReset, retryOnTimeout, retryOnUnreachable}
+	}
+
+	return settings
+}
+
+// retryable returns the kind of err if it is one of the kinds to retry on.
+func (s *retrySettings) retryable(err error) (string, bool) {
+	message := err.Error()
+	for _, kind := range s.retryOn {
+		for _, part := range retryErrorMessages[kind] {
+			if strings.Contains(message, part) {
+				return kind, true
+			}
+		}
+	}
+	return "", false
+}
+
+// retryConnect calls connect until it succeeds, fails with an error that is not retried,
+// the retry timeout passes or ctx is cancelled.
+func retryConnect(ctx context.Context, settings *retrySettings, host string, connect func() (*RemoteClient, error)) (*RemoteClient, error) {
+	if settings == nil {
+		return connect()
+	}
+
+	deadline := time.Now().Add(settings.timeout)
+	backoff := settings.initialBackoff
+
+	for attempt := 1; ; attempt++ {
+		client, err := connect()
+		if err == nil {
+			if attempt > 1 {
+				tflog.Info(ctx, "connection established after retrying", map[string]interface{}{
+					"host":     host,
+					"attempts": attempt,
+				})
+			}
+			return client, nil
+		}
+
+		kind, ok := settings.retryable(err)
+		if !ok {
+			return nil, err
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, fmt.Errorf("giving up after %d attempts: %s", attempt, err.Error())
+		}
+		if backoff > remaining {
+			backoff = remaining
+		}
+
+		tflog.Info(ctx, "connection attempt failed, retrying", map[string]interface{}{
+			"host":    host,
+			"attempt": attempt,
+			"kind":    kind,
+			"error":   err.Error(),
+			"backoff": backoff.String(),
+		})
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("cancelled while retrying: %s", err.Error())
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > settings.maxBackoff {
+			backoff = settings.maxBackoff
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryConnect(t *testing.T) {
+	settings := &retrySettings{
+		timeout:        time.Second,
+		initialBackoff: time.Millisecond,
+		maxBackoff:     2 * time.Millisecond,
+		retryOn:        []string{retryOnConnectionRefused},
+	}
+
+	attempts := 0
+	client, err := retryConnect(context.Background(), settings, "remotehost:22", func() (*RemoteClient, error) {
+		attempts++
+		if attempts < 3 {
+			return nil, errors.New("dial tcp 172.18.0.2:22: connect: connection refused")
+		}
+		return &RemoteClient{}, nil
+	})
+	if err != nil || client == nil {
+		t.Fatalf("expected a client, got error: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+
+	attempts = 0
+	_, err = retryConnect(context.Background(), settings, "remotehost:22", func() (*RemoteClient, error) {
+		attempts++
+		return nil, errors.New("ssh: handshake failed: ssh: unable to authenticate")
+	})
+	if err == nil || attempts != 1 {
+		t.Errorf("expected errors not configured to be retried to fail at once, got %d attempts", attempts)
+	}
+
+	settings.timeout = 10 * time.Millisecond
+	_, err = retryConnect(context.Background(), settings, "remotehost:22", func() (*RemoteClient, error) {
+		return nil, errors.New("connect: connection refused")
+	})
+	if err == nil || !strings.Contains(err.Error(), "giving up after") {
+		t.Errorf("expected to give up after the retry timeout, got %v", err)
+	}
+}
+
+func TestRetryConnectCancelled(t *testing.T) {
+	settings := &retrySettings{
+		timeout:        time.Minute,
+		initialBackoff: time.Minute,
+		maxBackoff:     time.Minute,
+		retryOn:        []string{retryOnTimeout},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := retryConnect(ctx, settings, "remotehost:22", func() (*RemoteClient, error) {
+		return nil, errors.New("dial tcp 172.18.0.2:22: i/o timeout")
+	})
+	if err == nil || !strings.Contains(err.Error(), "cancelled") {
+		t.Errorf("expected retrying to stop on cancellation, got %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSemaphore(t *testing.T) {
+	s := newSemaphore(1)
+
+	err := s.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("expected first acquire to succeed, got %s", err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = s.acquire(ctx)
+	if err == nil {
+		t.Fatal("expected acquire of a full semaphore to fail when the context is done")
+	}
+
+	s.release()
+	err = s.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("expected acquire after release to succeed, got %s", err.Error())
+	}
+}
+
+func TestSemaphoreUnlimited(t *testing.T) {
+	s := newSemaphore(0)
+	for i := 0; i < 10; i++ {
+		err := s.acquire(context.Background())
+		if err != nil {
+			t.Fatalf("expected unlimited semaphore to never block, got %s", err.Error())
+		}
+	}
+	s.release()
+}
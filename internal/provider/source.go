@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+)
+
+// contentDigest hashes and counts the bytes written to it.
+type contentDigest struct {
+	hash hash.Hash
+	size int64
+}
+
+func newContentDigest() *contentDigest {
+	return &contentDigest{hash: sha256.New()}
+}
+
+func (d *contentDigest) Write(p []byte) (int, error) {
+	d.size += int64(len(p))
+	return d.hash.Write(p)
+}
+
+// sha256 returns the hex encoded SHA-256 hash of the bytes written so far.
+func (d *contentDigest) sha256() string {
+	return hex.EncodeToString(d.hash.Sum(nil))
+}
+
+// localFileDigest returns the digest of the local file at path.
+func localFileDigest(path string) (*contentDigest, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open source: %s", err.Error())
+	}
+	defer file.Close()
+
+	digest := newContentDigest()
+	_, err = io.Copy(digest, file)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read source: %s", err.Error())
+	}
+	return digest, nil
+}
+
+// SHA256File returns the hex encoded SHA-256 hash of the file at path. It is computed by
+// sha256sum on the remote host, falling back to hashing the file read over SFTP when sha256sum
+// isn't available and privileges aren't needed.
+func (c *RemoteClient) SHA256File(path string, become *Become) (string, error) {
+	output, err := c.runCommand(shellCommand("sha256sum", "--", path), nil, become)
+	if err == nil {
+		fields := strings.Fields(string(output))
+		if len(fields) > 0 && len(fields[0]) == sha256.Size*2 {
+			return fields[0], nil
+		}
+		err = fmt.Errorf("unexpected output of sha256sum: %q", string(output))
+	}
+	if become != nil {
+		return "", err
+	}
+
+	sftpClient, err := c.GetSFTPClient()
+	if err != nil {
+		return "", err
+	}
+
+	file, err := sftpClient.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	digest := newContentDigest()
+	_, err = io.Copy(digest, file)
+	if err != nil {
+		return "", err
+	}
+	return digest.sha256(), nil
+}
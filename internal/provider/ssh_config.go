@@ -0,0 +1,336 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/kevinburke/ssh_config"
+	"golang.org/x/crypto/ssh"
+)
+
+// maxProxyJumpDepth limits how many ProxyJump directives of jump hosts are followed.
+const maxProxyJumpDepth = 8
+
+type sshConfigHost struct {
+	hostname      string
+	port          int
+	user          string
+	identityFiles []string
+	proxyJump     string
+}
+
+func loadSSHConfig(path string) (*ssh_config.Config, error) {
+	if path == "" {
+		path = "~/.ssh/config"
+	}
+
+	path, err := expandHomeDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read ssh config: %s", err.Error())
+	}
+	defer file.Close()
+
+	config, err := ssh_config.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse ssh config: %s", err.Error())
+	}
+
+	return config, nil
+}
+
+func resolveSSHConfigHost(config *ssh_config.Config, alias string) (sshConfigHost, error) {
+	host := sshConfigHost{}
+
+	hostname, err := config.Get(alias, "HostName")
+	if err != nil {
+		return host, fmt.Errorf("couldn't resolve HostName of %s: %s", alias, err.Error())
+	}
+	host.hostname = alias
+	if hostname != "" {
+		host.hostname = strings.ReplaceAll(hostname, "%h", alias)
+	}
+
+	port, err := config.Get(alias, "Port")
+	if err != nil {
+		return host, fmt.Errorf("couldn't resolve Port of %s: %s", alias, err.Error())
+	}
+	if port != "" {
+		host.port, err = strconv.Atoi(port)
+		if err != nil {
+			return host, fmt.Errorf("invalid Port of %s: %s", alias, port)
+		}
+	}
+
+	host.user, err = config.Get(alias, "User")
+	if err != nil {
+		return host, fmt.Errorf("couldn't resolve User of %s: %s", alias, err.Error())
+	}
+
+	identityFiles, err := config.GetAll(alias, "IdentityFile")
+	if err != nil {
+		return host, fmt.Errorf("couldn't resolve IdentityFile of %s: %s", alias, err.Error())
+	}
+	for _, identityFile := range identityFiles {
+		identityFile = strings.NewReplacer("%h", host.hostname, "%r", host.user, "%%", "%").Replace(identityFile)
+		identityFile, err = expandHomeDir(identityFile)
+		if err != nil {
+			return host, err
+		}
+		host.identityFiles = append(host.identityFiles, identityFile)
+	}
+
+	host.proxyJump, err = config.Get(alias, "ProxyJump")
+	if err != nil {
+		return host, fmt.Errorf("couldn't resolve ProxyJump of %s: %s", alias, err.Error())
+	}
+
+	return host, nil
+}
+
+// resolveProxyJump resolves a ProxyJump value, e.g. `user@bastion:2222,internal`, into jump hosts
+// in the order in which they are connected to. As with OpenSSH, ProxyJump of the first jump host
+// is followed as well.
+func resolveProxyJump(config *ssh_config.Config, proxyJump string, depth int) ([]sshConfigHost, error) {
+	if proxyJump == "" || strings.EqualFold(proxyJump, "none") {
+		return nil, nil
+	}
+	if depth > maxProxyJumpDepth {
+		return nil, fmt.Errorf("ProxyJump chain is longer than %d hosts", maxProxyJumpDepth)
+	}
+
+	hosts := []sshConfigHost{}
+	for i, spec := range strings.Split(proxyJump, ",") {
+		user, alias, port, err := parseJumpSpec(strings.TrimSpace(spec))
+		if err != nil {
+			return nil, err
+		}
+
+		host, err := resolveSSHConfigHost(config, alias)
+		if err != nil {
+			return nil, err
+		}
+		if user != "" {
+			host.user = user
+		}
+		if port != 0 {
+			host.port = port
+		}
+
+		if i == 0 {
+			previous, err := resolveProxyJump(config, host.proxyJump, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			hosts = append(hosts, previous...)
+		}
+		hosts = append(hosts, host)
+	}
+
+	return hosts, nil
+}
+
+func parseJumpSpec(spec string) (string, string, int, error) {
+	spec = strings.TrimPrefix(spec, "ssh://")
+
+	user := ""
+	if i := strings.LastIndex(spec, "@"); i >= 0 {
+		user = spec[:i]
+		spec = spec[i+1:]
+	}
+
+	port := 0
+	if i := strings.LastIndex(spec, ":"); i >= 0 {
+		var err error
+		port, err = strconv.Atoi(spec[i+1:])
+		if err != nil {
+			return "", "", 0, fmt.Errorf("invalid port in ProxyJump host %s", spec)
+		}
+		spec = spec[:i]
+	}
+
+	if spec == "" {
+		return "", "", 0, fmt.Errorf("invalid ProxyJump host")
+	}
+
+	return user, spec, port, nil
+}
+
+// identityFileSigners parses identity files, skipping those that do not exist as OpenSSH does.
+func identityFileSigners(identityFiles []string, passphrase string) ([]ssh.Signer, error) {
+	signers := []ssh.Signer{}
+	for _, identityFile := range identityFiles {
+		content, err := ioutil.ReadFile(identityFile)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read identity file: %s", err.Error())
+		}
+
+		signer, err := parsePrivateKey(content, passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't create a ssh client config from identity file %s: %s", identityFile, err.Error())
+		}
+		signers = append(signers, signer)
+	}
+	return signers, nil
+}
+
+func expandHomeDir(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.Contains(path, "%d") {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("couldn't locate home directory: %s", err.Error())
+	}
+
+	path = strings.ReplaceAll(path, "%d", home)
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		path = filepath.Join(home, path[1:])
+	}
+	return path, nil
+}
+
+// SSHConfigProxyConnectionsFromResourceData returns jump hosts of the ProxyJump directive
+// configured for the host of the connection in ssh_config mode. Jump hosts authenticate with
+// the credentials of the connection and their own identity files.
+func SSHConfigProxyConnectionsFromResourceData(ctx context.Context, d *schema.ResourceData) ([]string, []*ssh.ClientConfig, error) {
+	prefixKey := func(key string) string {
+		return fmt.Sprintf("conn.0.%s", key)
+	}
+
+	if !d.Get(prefixKey("ssh_config")).(bool) {
+		return nil, nil, nil
+	}
+
+	config, err := loadSSHConfig(resourceStringWithDefault(d, prefixKey("ssh_config_path"), ""))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	target, err := resolveSSHConfigHost(config, d.Get(prefixKey("host")).(string))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	jumpHosts, err := resolveProxyJump(config, target.proxyJump, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// A pinned host_key belongs to the target host only
+	hostKeySettings := hostKeySettingsFromResourceData(prefixKey, d)
+	hostKeySettings.hostKey = ""
+
+	hosts := []string{}
+	clientConfigs := []*ssh.ClientConfig{}
+	for _, jumpHost := range jumpHosts {
+		port := jumpHost.port
+		if port == 0 {
+			port = defaultPort
+		}
+		host := fmt.Sprintf("%s:%d", jumpHost.hostname, port)
+
+		hostKeyCallback, hostKeyAlgorithms, err := hostKeySettings.callback(host)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		auth, err := authFromResourceData(prefixKey, d, jumpHost.identityFiles)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		clientConfig := &ssh.ClientConfig{
+			User:            jumpHost.user,
+			Auth:            auth,
+			HostKeyCallback: hostKeyCallback,
+		}
+		if clientConfig.User == "" {
+			clientConfig.User = resourceStringWithDefault(d, prefixKey("user"), target.user)
+		}
+		setAlgorithmsFromResourceData(prefixKey, d, clientConfig)
+		if len(clientConfig.HostKeyAlgorithms) == 0 {
+			clientConfig.HostKeyAlgorithms = hostKeyAlgorithms
+		}
+
+		timeout, ok := d.GetOk(prefixKey("timeout"))
+		if ok {
+			clientConfig.Timeout = time.Duration(timeout.(int)) * time.Millisecond
+		}
+
+		hosts = append(hosts, host)
+		clientConfigs = append(clientConfigs, clientConfig)
+	}
+
+	return hosts, clientConfigs, nil
+}
+
+// configuredExplicitly returns whether key, e.g. conn.0.port, is set in the configuration of d
+// rather than filled in from its default. Without a raw configuration, as when refreshing and
+// destroying, the value recorded in key_explicit by recordExplicitPort is used. The connection of
+// the provider has none, so for it only a value other than defaultValue counts as explicit.
+func configuredExplicitly(d *schema.ResourceData, key string, defaultValue interface{}) bool {
+	config := d.GetRawConfig()
+	if config.IsNull() {
+		recorded, _ := d.Get(key + "_explicit").(bool)
+		return recorded || d.Get(key) != defaultValue
+	}
+	return !rawConfigValue(config, key).IsNull()
+}
+
+// recordExplicitPort records in conn.0.port_explicit whether the port of the connection of d is
+// set in its configuration, which is only available when planning and applying.
+func recordExplicitPort(d *schema.ResourceData) {
+	config := d.GetRawConfig()
+	if config.IsNull() {
+		return
+	}
+
+	conns := d.Get("conn").([]interface{})
+	if len(conns) == 0 || conns[0] == nil {
+		return
+	}
+	conns[0].(map[string]interface{})["port_explicit"] = !rawConfigValue(config, "conn.0.port").IsNull()
+	d.Set("conn", conns)
+}
+
+// rawConfigValue returns the value at key in config, null when it isn't set.
+func rawConfigValue(config cty.Value, key string) cty.Value {
+	value := config
+	for _, part := range strings.Split(key, ".") {
+		if value.IsNull() || !value.IsKnown() {
+			return cty.NullVal(cty.DynamicPseudoType)
+		}
+
+		index, err := strconv.Atoi(part)
+		if err == nil && (value.Type().IsListType() || value.Type().IsTupleType()) {
+			if index >= value.LengthInt() {
+				return cty.NullVal(cty.DynamicPseudoType)
+			}
+			value = value.Index(cty.NumberIntVal(int64(index)))
+			continue
+		}
+
+		if !value.Type().IsObjectType() || !value.Type().HasAttribute(part) {
+			return cty.NullVal(cty.DynamicPseudoType)
+		}
+		value = value.GetAttr(part)
+	}
+	return value
+}
@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestRawConfigValue(t *testing.T) {
+	config := cty.ObjectVal(map[string]cty.Value{
+		"conn": cty.ListVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{
+				"host": cty.StringVal("remotehost"),
+				"port": cty.NumberIntVal(22),
+				"user": cty.NullVal(cty.String),
+			}),
+		}),
+		"proxy_conn": cty.ListValEmpty(cty.Object(map[string]cty.Type{
+			"port": cty.Number,
+		})),
+	})
+
+	if !rawConfigValue(config, "conn.0.port").RawEquals(cty.NumberIntVal(22)) {
+		t.Error("expected an explicit port of 22 to be found")
+	}
+	for _, key := range []string{"conn.0.user", "conn.1.port", "conn.0.missing", "proxy_conn.0.port"} {
+		if !rawConfigValue(config, key).IsNull() {
+			t.Errorf("expected %s to be null", key)
+		}
+	}
+}
+
+func TestConfiguredExplicitlyRecorded(t *testing.T) {
+	// Like when refreshing and destroying, there is no raw configuration
+	d := schema.TestResourceDataRaw(t, resourceRemoteFile().Schema, map[string]interface{}{
+		"conn": []interface{}{
+			map[string]interface{}{
+				"host": "target",
+				"port": 22,
+			},
+		},
+		"path": "/tmp/file",
+	})
+
+	if configuredExplicitly(d, "conn.0.port", defaultPort) {
+		t.Error("expected the default port not to count as explicit")
+	}
+
+	conns := d.Get("conn").([]interface{})
+	conns[0].(map[string]interface{})["port_explicit"] = true
+	err := d.Set("conn", conns)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !configuredExplicitly(d, "conn.0.port", defaultPort) {
+		t.Error("expected the recorded explicit port to count as explicit")
+	}
+}
@@ -0,0 +1,148 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+// RemoteSymlink describes what is at the path of a symbolic link.
+type RemoteSymlink struct {
+	// Exists is true when anything is at the path, IsSymlink only when it is a symbolic link.
+	Exists    bool
+	IsSymlink bool
+	Target    string
+}
+
+// ReadSymlink returns the target of the symbolic link at linkPath without following it.
+func (c *RemoteClient) ReadSymlink(linkPath string, become *Become) (*RemoteSymlink, error) {
+	if become != nil {
+		return c.ReadSymlinkShell(linkPath, become)
+	}
+	return c.ReadSymlinkSFTP(linkPath)
+}
+
+func (c *RemoteClient) ReadSymlinkSFTP(linkPath string) (*RemoteSymlink, error) {
+	sftpClient, err := c.GetSFTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := sftpClient.Lstat(linkPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return &RemoteSymlink{Exists: false}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		return &RemoteSymlink{Exists: true, IsSymlink: false}, nil
+	}
+
+	target, err := sftpClient.ReadLink(linkPath)
+	if err != nil {
+		return nil, err
+	}
+	return &RemoteSymlink{Exists: true, IsSymlink: true, Target: target}, nil
+}
+
+// ReadSymlinkShell prints the kind of file at the path on the first line of the output followed
+// by the target of the link.
+func (c *RemoteClient) ReadSymlinkShell(linkPath string, become *Become) (*RemoteSymlink, error) {
+	cmd := fmt.Sprintf("if %s; then echo symlink && %s; elif %s; then echo other; else echo missing; fi",
+		shellCommand("test", "-L", linkPath),
+		shellCommand("readlink", "--", linkPath),
+		shellCommand("test", "-e", linkPath))
+	output, err := c.runCommand(cmd, nil, become)
+	if err != nil {
+		return nil, err
+	}
+
+	kind, target := string(output), ""
+	newline := strings.IndexByte(kind, '\n')
+	if newline >= 0 {
+		kind, target = kind[:newline], strings.TrimSuffix(kind[newline+1:], "\n")
+	}
+
+	switch kind {
+	case "symlink":
+		return &RemoteSymlink{Exists: true, IsSymlink: true, Target: target}, nil
+	case "other":
+		return &RemoteSymlink{Exists: true, IsSymlink: false}, nil
+	case "missing":
+		return &RemoteSymlink{Exists: false}, nil
+	default:
+		return nil, fmt.Errorf("unexpected kind of %s: %q", linkPath, kind)
+	}
+}
+
+// WriteSymlinkAtomic points the symbolic link at linkPath to target by creating a temporary link
+// in the same directory and renaming it over linkPath, so the link is always present. A regular
+// file at linkPath is replaced too, a directory isn't.
+func (c *RemoteClient) WriteSymlinkAtomic(target string, linkPath string, become *Become) error {
+	marker, err := randomMarker()
+	if err != nil {
+		return err
+	}
+	tempPath := path.Join(path.Dir(linkPath), fmt.Sprintf(".%s.%s", path.Base(linkPath), marker))
+
+	if become != nil {
+		return c.WriteSymlinkAtomicShell(target, linkPath, tempPath, become)
+	}
+	return c.WriteSymlinkAtomicSFTP(target, linkPath, tempPath)
+}
+
+func (c *RemoteClient) WriteSymlinkAtomicSFTP(target string, linkPath string, tempPath string) error {
+	sftpClient, err := c.GetSFTPClient()
+	if err != nil {
+		return err
+	}
+
+	err = sftpClient.Symlink(target, tempPath)
+	if err != nil {
+		return fmt.Errorf("couldn't create temporary link: %s", err.Error())
+	}
+
+	err = sftpClient.PosixRename(tempPath, linkPath)
+	if err != nil {
+		sftpClient.Remove(tempPath)
+		return fmt.Errorf("couldn't replace link: %s", err.Error())
+	}
+	return nil
+}
+
+// WriteSymlinkAtomicShell uses mv -T so a link to a directory at linkPath is replaced instead of
+// the temporary link being moved into the directory.
+func (c *RemoteClient) WriteSymlinkAtomicShell(target string, linkPath string, tempPath string, become *Become) error {
+	cmd := fmt.Sprintf("if %s && %s; then exit 0; fi; %s; exit 1",
+		shellCommand("ln", "-s", "--", target, tempPath),
+		shellCommand("mv", "-T", "-f", "--", tempPath, linkPath),
+		shellCommand("rm", "-f", "--", tempPath))
+	_, err := c.runCommand(cmd, nil, become)
+	return err
+}
+
+// DeleteSymlink removes the symbolic link at linkPath, leaving anything else at the path alone.
+func (c *RemoteClient) DeleteSymlink(linkPath string, become *Become) error {
+	if become != nil {
+		cmd := fmt.Sprintf("if %s; then %s; fi", shellCommand("test", "-L", linkPath), shellCommand("rm", "-f", "--", linkPath))
+		_, err := c.runCommand(cmd, nil, become)
+		return err
+	}
+
+	link, err := c.ReadSymlinkSFTP(linkPath)
+	if err != nil {
+		return err
+	}
+	if !link.IsSymlink {
+		return nil
+	}
+
+	sftpClient, err := c.GetSFTPClient()
+	if err != nil {
+		return err
+	}
+	return sftpClient.Remove(linkPath)
+}
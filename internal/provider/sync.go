@@ -0,0 +1,235 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// syncPatternMatches returns whether rel, a slash separated path relative to the synced
+// directory, or one of its parent directories matches any of patterns. Patterns without a slash
+// are matched against single names, those with a slash against the path from the top.
+func syncPatternMatches(patterns []string, rel string) bool {
+	parts := strings.Split(rel, "/")
+	for _, pattern := range patterns {
+		for i := range parts {
+			name := parts[i]
+			if strings.Contains(pattern, "/") {
+				name = strings.Join(parts[:i+1], "/")
+			}
+			ok, _ := path.Match(pattern, name)
+			if ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// syncIncluded returns whether the file at rel is synced, which it is when it matches include,
+// or include is empty, and doesn't match exclude.
+func syncIncluded(include []string, exclude []string, rel string) bool {
+	if len(include) > 0 && !syncPatternMatches(include, rel) {
+		return false
+	}
+	return !syncPatternMatches(exclude, rel)
+}
+
+func validateGlob(value interface{}, key string) ([]string, []error) {
+	_, err := path.Match(value.(string), "")
+	if err != nil {
+		return nil, []error{fmt.Errorf("expected %s to be a glob pattern, got %q: %s", key, value, err.Error())}
+	}
+	return nil, nil
+}
+
+// localSyncFile is a regular file of the local directory to sync.
+type localSyncFile struct {
+	path   string
+	mode   os.FileMode
+	sha256 string
+}
+
+// localSyncTree returns the files of dir selected by include and exclude, by their slash
+// separated path relative to dir. Symbolic links to files are followed.
+func localSyncTree(dir string, include []string, exclude []string) (map[string]localSyncFile, error) {
+	files := map[string]localSyncFile{}
+	err := filepath.Walk(dir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." || info.IsDir() {
+			return nil
+		}
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			info, err = os.Stat(filePath)
+			if err != nil {
+				return err
+			}
+		}
+		if !info.Mode().IsRegular() || !syncIncluded(include, exclude, rel) {
+			return nil
+		}
+
+		digest, err := localFileDigest(filePath)
+		if err != nil {
+			return err
+		}
+		files[rel] = localSyncFile{
+			path:   filePath,
+			mode:   info.Mode().Perm(),
+			sha256: digest.sha256(),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read source: %s", err.Error())
+	}
+	return files, nil
+}
+
+// HashTree returns the SHA-256 hashes of the regular files in dir by their slash separated path
+// relative to dir. They are computed by sha256sum on the remote host, falling back to hashing
+// the files read over SFTP.
+func (c *RemoteClient) HashTree(dir string) (map[string]string, error) {
+	cmd := fmt.Sprintf("cd %s && find . -type f -exec sha256sum -- {} +", shellQuote(dir))
+	output, err := c.runCommand(cmd, nil, nil)
+	if err == nil {
+		hashes, err := parseSHA256Sums(output)
+		if err == nil {
+			return hashes, nil
+		}
+	}
+	return c.HashTreeSFTP(dir)
+}
+
+// parseSHA256Sums parses the output of sha256sum for paths starting with "./". Lines of names
+// with a newline or backslash start with a backslash and have them escaped.
+func parseSHA256Sums(output []byte) (map[string]string, error) {
+	hashes := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		escaped := strings.HasPrefix(line, "\\")
+		if escaped {
+			line = line[1:]
+		}
+
+		fields := strings.SplitN(line, "  ", 2)
+		if len(fields) != 2 || len(fields[0]) != 64 || !strings.HasPrefix(fields[1], "./") {
+			return nil, fmt.Errorf("unexpected output of sha256sum: %q", scanner.Text())
+		}
+
+		name := fields[1][2:]
+		if escaped {
+			name = strings.NewReplacer(`\\`, `\`, `\n`, "\n").Replace(name)
+		}
+		hashes[name] = fields[0]
+	}
+	return hashes, scanner.Err()
+}
+
+func (c *RemoteClient) HashTreeSFTP(dir string) (map[string]string, error) {
+	sftpClient, err := c.GetSFTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := map[string]string{}
+	walker := sftpClient.Walk(dir)
+	for walker.Step() {
+		err = walker.Err()
+		if err != nil {
+			return nil, err
+		}
+		if !walker.Stat().Mode().IsRegular() {
+			continue
+		}
+
+		file, err := sftpClient.Open(walker.Path())
+		if err != nil {
+			return nil, err
+		}
+		digest := newContentDigest()
+		_, err = io.Copy(digest, file)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		rel := strings.TrimPrefix(strings.TrimPrefix(walker.Path(), dir), "/")
+		hashes[rel] = digest.sha256()
+	}
+	return hashes, nil
+}
+
+// UploadFileSFTP writes the local file at localPath to remotePath with mode, creating missing
+// parent directories.
+func (c *RemoteClient) UploadFileSFTP(localPath string, remotePath string, mode os.FileMode) error {
+	sftpClient, err := c.GetSFTPClient()
+	if err != nil {
+		return err
+	}
+
+	err = sftpClient.MkdirAll(path.Dir(remotePath))
+	if err != nil {
+		return fmt.Errorf("couldn't create directory %s: %s", path.Dir(remotePath), err.Error())
+	}
+
+	file, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	err = c.WriteFileSFTPFrom(file, remotePath)
+	if err != nil {
+		return fmt.Errorf("couldn't write %s: %s", remotePath, err.Error())
+	}
+
+	return sftpClient.Chmod(remotePath, mode)
+}
+
+// RemoveSyncedFilesSFTP removes the files at rels relative to dir and the directories below dir
+// left empty by that.
+func (c *RemoteClient) RemoveSyncedFilesSFTP(dir string, rels []string) error {
+	sftpClient, err := c.GetSFTPClient()
+	if err != nil {
+		return err
+	}
+
+	parents := map[string]bool{}
+	for _, rel := range rels {
+		err = sftpClient.Remove(path.Join(dir, rel))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("couldn't remove %s: %s", path.Join(dir, rel), err.Error())
+		}
+		for parent := path.Dir(rel); parent != "."; parent = path.Dir(parent) {
+			parents[path.Join(dir, parent)] = true
+		}
+	}
+
+	// Outermost first, as expected by RemoveEmptyDirectoriesSFTP
+	dirs := []string{}
+	for parent := range parents {
+		dirs = append(dirs, parent)
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		return strings.Count(dirs[i], "/") < strings.Count(dirs[j], "/")
+	})
+	return c.RemoveEmptyDirectoriesSFTP(dirs)
+}
@@ -0,0 +1,91 @@
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSyncIncluded(t *testing.T) {
+	tests := []struct {
+		include  []string
+		exclude  []string
+		rel      string
+		expected bool
+	}{
+		{nil, nil, "index.html", true},
+		{[]string{"*.html"}, nil, "index.html", true},
+		{[]string{"*.html"}, nil, "docs/index.html", true},
+		{[]string{"*.html"}, nil, "style.css", false},
+		{[]string{"assets"}, nil, "assets/css/style.css", true},
+		{[]string{"assets/*.css"}, nil, "assets/style.css", true},
+		{[]string{"assets/*.css"}, nil, "docs/assets/style.css", false},
+		{nil, []string{".git"}, ".git/config", false},
+		{nil, []string{"*.tmp"}, "cache/page.tmp", false},
+		{[]string{"*.html"}, []string{"drafts"}, "drafts/index.html", false},
+	}
+
+	for _, test := range tests {
+		included := syncIncluded(test.include, test.exclude, test.rel)
+		if included != test.expected {
+			t.Errorf("expected %t for %s with include %q and exclude %q, got %t", test.expected, test.rel, test.include, test.exclude, included)
+		}
+	}
+}
+
+func TestParseSHA256Sums(t *testing.T) {
+	hash := "458d57f1b3576125be2f8322017c416a3865a343e58aa497a70f219bb99f2bb5"
+	output := hash + "  ./index.html\n" +
+		hash + "  ./docs/a b.html\n" +
+		"\\" + hash + "  ./line\\nbreak\\\\\n"
+
+	hashes, err := parseSHA256Sums([]byte(output))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	expected := map[string]string{
+		"index.html":    hash,
+		"docs/a b.html": hash,
+		"line\nbreak\\": hash,
+	}
+	if !reflect.DeepEqual(hashes, expected) {
+		t.Errorf("expected %q, got %q", expected, hashes)
+	}
+
+	_, err = parseSHA256Sums([]byte("sha256sum: ./file: Permission denied\n"))
+	if err == nil {
+		t.Error("expected unexpected output to fail")
+	}
+}
+
+func TestLocalSyncTree(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"index.html", "assets/style.css", ".git/config"} {
+		err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		err = os.WriteFile(filepath.Join(dir, name), []byte("resource_13"), 0640)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+
+	files, err := localSyncTree(dir, nil, []string{".git"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files, got %d", len(files))
+	}
+	file, ok := files["assets/style.css"]
+	if !ok {
+		t.Fatal("expected assets/style.css to be synced")
+	}
+	if file.sha256 != "458d57f1b3576125be2f8322017c416a3865a343e58aa497a70f219bb99f2bb5" || file.mode != 0640 {
+		t.Errorf("unexpected hash %s or mode %o", file.sha256, file.mode)
+	}
+}
@@ -0,0 +1,149 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// userDatabase maps user and group names of a remote host to their IDs and back. It is loaded
+// from /etc/passwd and /etc/group once per connection, entries missing there, e.g. those of
+// directory services, are looked up with getent and cached, as are those getent doesn't know.
+type userDatabase struct {
+	mux        sync.Mutex
+	loaded     bool
+	userNames  map[string]string
+	userIDs    map[string]string
+	groupNames map[string]string
+	groupIDs   map[string]string
+	// misses are the getent queries which found no entry, keyed by database and key
+	misses map[string]bool
+}
+
+func newUserDatabase() *userDatabase {
+	return &userDatabase{
+		userNames:  map[string]string{},
+		userIDs:    map[string]string{},
+		groupNames: map[string]string{},
+		groupIDs:   map[string]string{},
+		misses:     map[string]bool{},
+	}
+}
+
+// parse adds the name and ID of every entry of a passwd or group file to names and ids.
+func (db *userDatabase) parse(content []byte, names map[string]string, ids map[string]string) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) < 3 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		_, ok := names[fields[2]]
+		if !ok {
+			names[fields[2]] = fields[0]
+		}
+		ids[fields[0]] = fields[2]
+	}
+}
+
+func (c *RemoteClient) loadUserDatabase() {
+	c.users.mux.Lock()
+	defer c.users.mux.Unlock()
+
+	if c.users.loaded {
+		return
+	}
+	c.users.loaded = true
+
+	// Hosts without these files, e.g. chrooted SFTP servers, are served by getent
+	passwd, err := c.ReadFileSFTP("/etc/passwd")
+	if err == nil {
+		c.users.parse([]byte(passwd), c.users.userNames, c.users.userIDs)
+	}
+	group, err := c.ReadFileSFTP("/etc/group")
+	if err == nil {
+		c.users.parse([]byte(group), c.users.groupNames, c.users.groupIDs)
+	}
+}
+
+// lookup returns the value of key in entries, falling back to getent of database.
+func (c *RemoteClient) lookup(database string, key string, entries map[string]string) (string, bool) {
+	c.loadUserDatabase()
+
+	query := database + ":" + key
+
+	c.users.mux.Lock()
+	value, ok := entries[key]
+	missed := c.users.misses[query]
+	c.users.mux.Unlock()
+	if ok || missed {
+		return value, ok
+	}
+
+	output, err := c.runCommand(shellCommand("getent", database, key), nil, nil)
+
+	c.users.mux.Lock()
+	defer c.users.mux.Unlock()
+	if err == nil {
+		if database == "passwd" {
+			c.users.parse(output, c.users.userNames, c.users.userIDs)
+		} else {
+			c.users.parse(output, c.users.groupNames, c.users.groupIDs)
+		}
+	}
+	value, ok = entries[key]
+	if !ok {
+		c.users.misses[query] = true
+	}
+	return value, ok
+}
+
+// unknownName is the name of users and groups without an entry, like printed by stat.
+const unknownName = "UNKNOWN"
+
+func (c *RemoteClient) userName(uid uint32) string {
+	name, ok := c.lookup("passwd", strconv.FormatUint(uint64(uid), 10), c.users.userNames)
+	if !ok {
+		return unknownName
+	}
+	return name
+}
+
+func (c *RemoteClient) groupName(gid uint32) string {
+	name, ok := c.lookup("group", strconv.FormatUint(uint64(gid), 10), c.users.groupNames)
+	if !ok {
+		return unknownName
+	}
+	return name
+}
+
+// userID returns the ID of owner, which is either a user name or a numeric ID.
+func (c *RemoteClient) userID(owner string) (int, error) {
+	uid, err := strconv.Atoi(owner)
+	if err == nil {
+		return uid, nil
+	}
+
+	id, ok := c.lookup("passwd", owner, c.users.userIDs)
+	if !ok {
+		return 0, fmt.Errorf("no user named %s", owner)
+	}
+	return strconv.Atoi(id)
+}
+
+// groupID returns the ID of group, which is either a group name or a numeric ID.
+func (c *RemoteClient) groupID(group string) (int, error) {
+	gid, err := strconv.Atoi(group)
+	if err == nil {
+		return gid, nil
+	}
+
+	id, ok := c.lookup("group", group, c.users.groupIDs)
+	if !ok {
+		return 0, fmt.Errorf("no group named %s", group)
+	}
+	return strconv.Atoi(id)
+}
@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestUserDatabaseParse(t *testing.T) {
+	db := newUserDatabase()
+	db.parse([]byte(`root:x:0:0:root:/root:/bin/bash
+# comment:x:1:1
+bob:x:1000:1000::/home/bob:/bin/sh
+toor:x:0:0:root:/root:/bin/sh
+invalid
+`), db.userNames, db.userIDs)
+
+	expectedNames := map[string]string{"0": "root", "1000": "bob"}
+	for id, name := range expectedNames {
+		if db.userNames[id] != name {
+			t.Errorf("expected user %s to be named %s, got %s", id, name, db.userNames[id])
+		}
+	}
+
+	expectedIDs := map[string]string{"root": "0", "bob": "1000", "toor": "0"}
+	for name, id := range expectedIDs {
+		if db.userIDs[name] != id {
+			t.Errorf("expected user %s to have ID %s, got %s", name, id, db.userIDs[name])
+		}
+	}
+
+	if len(db.userNames) != 2 || len(db.userIDs) != 3 {
+		t.Errorf("expected 2 names and 3 IDs, got %v and %v", db.userNames, db.userIDs)
+	}
+}
+
+func TestUserDatabaseCachesMisses(t *testing.T) {
+	server := newTestSSHServer(t)
+	d := server.resourceData(t, nil)
+	c := newTestAPIClient(t, time.Minute)
+
+	client, err := c.getRemoteClient(context.Background(), d)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer c.closeRemoteClient(d)
+
+	for i := 0; i < 3; i++ {
+		if name := client.userName(4242424); name != unknownName {
+			t.Errorf("expected user 4242424 to be %s, got %s", unknownName, name)
+		}
+		_, err = client.groupID("no-such-group")
+		if err == nil {
+			t.Error("expected no-such-group to be unknown")
+		}
+	}
+
+	if server.commandCount() != 2 {
+		t.Errorf("expected getent to run once per missing entry, got %v", server.commands)
+	}
+}
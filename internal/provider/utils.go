@@ -33,3 +33,31 @@ func writeFileToHost(host string, filename string, content string, group string,
 	}()
 	session.Run(fmt.Sprintf("cat /dev/stdin | tee %s && chgrp %s %s && chown %s %s", filename, group, filename, user, filename))
 }
+
+func runOnHost(host string, cmd string) {
+	err := checkOnHost(host, cmd)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// checkOnHost runs cmd on host and returns its error, e.g. to check for a file with test.
+func checkOnHost(host string, cmd string) error {
+	sshClient, err := ssh.Dial("tcp", host, &ssh.ClientConfig{
+		User:            "root",
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Auth:            []ssh.AuthMethod{ssh.Password("password")},
+	})
+	if err != nil {
+		return err
+	}
+	defer sshClient.Close()
+
+	session, err := sshClient.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	return session.Run(cmd)
+}
@@ -36,6 +36,8 @@ func main() {
 
 	opts := &plugin.ServeOpts{ProviderFunc: provider.New(version)}
 
+	defer provider.CloseConnections()
+
 	if debugMode {
 		err := plugin.Debug(context.Background(), "registry.terraform.io/tmscer/remote", opts)
 		if err != nil {